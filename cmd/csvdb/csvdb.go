@@ -23,6 +23,8 @@ const (
 	cmdLoadTable  = `\load`
 	cmdTableList  = `\list`
 	cmdDroupTable = `\drop`
+	cmdDescribe   = `\describe`
+	flagYAML      = "--yaml"
 	cmdHelp       = `\help`
 	welcomeQuery  = "~# "
 )
@@ -34,9 +36,14 @@ var (
 		desc string
 	}{
 		{cmd: cmdHelp, desc: "Show the help"},
-		{cmd: cmdTableList, desc: "Show available loaded tables"},
-		{cmd: cmdLoadTable, desc: fmt.Sprintf("Load the table. Format: '%s <csv-path> <yaml-description-path>'", cmdLoadTable)},
-		{cmd: cmdDroupTable, desc: fmt.Sprintf("Drop the table. Format: '%s <tablename>'", cmdDroupTable)},
+		{cmd: cmdTableList, desc: "Show available tables and views (marked with '(view)')"},
+		{cmd: cmdLoadTable, desc: fmt.Sprintf(
+			"Load the table. Format: '%s <csv-path> <yaml-description-path>' or '%s <csv-path> [tablename]' to infer field types",
+			cmdLoadTable, cmdLoadTable)},
+		{cmd: cmdDescribe, desc: fmt.Sprintf(
+			"Describe fields of the table. Format: '%s [%s] <tablename>', %s prints the yaml description",
+			cmdDescribe, flagYAML, flagYAML)},
+		{cmd: cmdDroupTable, desc: fmt.Sprintf("Drop the table or the view. Format: '%s <tablename>'", cmdDroupTable)},
 	}
 )
 
@@ -113,20 +120,9 @@ func handleInput(ctx context.Context, logger *zap.Logger, a *app.App, f table.Fo
 	case in == `\q`:
 		return
 	case strings.HasPrefix(in, cmdLoadTable):
-		in = strings.TrimSpace(strings.TrimPrefix(in, cmdLoadTable))
-		args := strings.Split(in, " ")
-		if len(args) != 2 {
-			fmt.Printf("wrong syntax for %s: '%s'\n", cmdLoadTable, in)
-		}
-		t, err := loader.LoadFromCSV(args[0], args[1])
-		if err != nil {
-			fmt.Printf("error when loading from csv: %v\n", err)
-		}
-		if err = a.LoadTable(t); err != nil {
-			fmt.Printf("error when loading table: %v\n", err)
-			logger.Error("error when loading table",
-				zap.Error(err))
-		}
+		loadTable(logger, a, strings.TrimSpace(strings.TrimPrefix(in, cmdLoadTable)))
+	case strings.HasPrefix(in, cmdDescribe):
+		describeTable(ctx, a, f, strings.TrimSpace(strings.TrimPrefix(in, cmdDescribe)))
 	case in == cmdTableList:
 		fmt.Println(strings.Join(a.TableList(), "\n"))
 	case in == cmdHelp:
@@ -172,6 +168,71 @@ func handleInput(ctx context.Context, logger *zap.Logger, a *app.App, f table.Fo
 	}
 }
 
+func loadTable(logger *zap.Logger, a *app.App, in string) {
+	var t table.Table
+	var err error
+
+	args := strings.Fields(in)
+	switch {
+	case len(args) == 2 && (strings.HasSuffix(args[1], ".yaml") || strings.HasSuffix(args[1], ".yml")):
+		t, err = loader.LoadFromCSV(args[0], args[1])
+	case len(args) == 2:
+		t, err = loader.LoadWithInference(args[0], args[1])
+	case len(args) == 1:
+		t, err = loader.LoadWithInference(args[0], "")
+	default:
+		fmt.Printf("wrong syntax for %s: '%s'\n", cmdLoadTable, in)
+
+		return
+	}
+	if err != nil {
+		fmt.Printf("error when loading from csv: %v\n", err)
+
+		return
+	}
+	if err = a.LoadTable(t); err != nil {
+		fmt.Printf("error when loading table: %v\n", err)
+		logger.Error("error when loading table",
+			zap.Error(err))
+	}
+}
+
+func describeTable(ctx context.Context, a *app.App, f table.Formatter, in string) {
+	args := strings.Fields(in)
+	switch {
+	case len(args) == 2 && args[0] == flagYAML:
+		t, err := a.Table(args[1])
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+
+			return
+		}
+		config, err := loader.MarshalConfig(t)
+		if err != nil {
+			fmt.Printf("error when describing table: %v\n", err)
+
+			return
+		}
+		fmt.Print(string(config))
+	case len(args) == 1:
+		t, err := a.Describe(args[0])
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+
+			return
+		}
+		output, err := f.Format(ctx, t)
+		if err != nil {
+			fmt.Printf("error when describing table: %v\n", err)
+
+			return
+		}
+		fmt.Println(output)
+	default:
+		fmt.Printf("wrong syntax for %s: '%s'\n", cmdDescribe, in)
+	}
+}
+
 func main() {
 	log := getLogger()
 
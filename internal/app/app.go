@@ -10,26 +10,39 @@ import (
 	"github.com/stepan2volkov/csvdb/internal/app/parser"
 	"github.com/stepan2volkov/csvdb/internal/app/scanner"
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/aggregate"
+	"github.com/stepan2volkov/csvdb/internal/app/table/dml"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/join"
+	"github.com/stepan2volkov/csvdb/internal/app/table/set"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stepan2volkov/csvdb/internal/app/table/window"
 )
 
 func NewApp(logger *zap.Logger) *App {
 	return &App{
 		logger: logger,
 		tables: make(map[string]table.Table),
+		views:  make(map[string]parser.SelectStmt),
 	}
 }
 
 type App struct {
 	tables map[string]table.Table
+	// views - запросы представлений, которые выполняются при каждом обращении к ним
+	views  map[string]parser.SelectStmt
 	logger *zap.Logger
 }
 
+// viewMarker отличает представления от таблиц в списке TableList
+const viewMarker = " (view)"
+
 func (a *App) LoadTable(t table.Table) error {
 	if strings.TrimSpace(t.Name) == "" {
 		return fmt.Errorf("table name should'n be empty")
 	}
-	if _, found := a.tables[t.Name]; found {
-		return fmt.Errorf("table '%s' has already exist", t.Name)
+	if err := a.checkNameIsFree(t.Name); err != nil {
+		return err
 	}
 	a.tables[t.Name] = t
 
@@ -37,15 +50,71 @@ func (a *App) LoadTable(t table.Table) error {
 }
 
 func (a *App) TableList() []string {
-	ret := make([]string, 0, len(a.tables))
+	ret := make([]string, 0, len(a.tables)+len(a.views))
 	for tableName := range a.tables {
 		ret = append(ret, tableName)
 	}
+	for viewName := range a.views {
+		ret = append(ret, viewName+viewMarker)
+	}
 
 	return ret
 }
 
+// checkNameIsFree проверяет, что наименование не занято таблицей или представлением
+func (a *App) checkNameIsFree(name string) error {
+	if _, found := a.tables[name]; found {
+		return fmt.Errorf("table '%s' has already exist", name)
+	}
+	if _, found := a.views[name]; found {
+		return fmt.Errorf("view '%s' has already exist", name)
+	}
+
+	return nil
+}
+
+func (a *App) Table(tableName string) (table.Table, error) {
+	t, found := a.tables[tableName]
+	if !found {
+		return table.Table{}, fmt.Errorf("table '%s' doesn't exist", tableName)
+	}
+
+	return t, nil
+}
+
+// Describe возвращает таблицу с описанием полей таблицы tableName
+func (a *App) Describe(tableName string) (table.Table, error) {
+	t, err := a.Table(tableName)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	cols := []table.Column{
+		{Field: table.Field{Name: "field", Type: table.FieldTypeString}},
+		{Field: table.Field{Name: "type", Type: table.FieldTypeString}},
+		{Field: table.Field{Name: "nullable", Type: table.FieldTypeBool}},
+	}
+	for _, col := range t.Columns {
+		fieldType := col.Field.Type.String()
+		switch col.Field.Type {
+		case table.FieldTypeDate, table.FieldTypeDatetime:
+			fieldType = fmt.Sprintf("%s(%s)", fieldType, col.Field.Layout)
+		case table.FieldTypeDecimal:
+			fieldType = fmt.Sprintf("%s(%d)", fieldType, col.Field.Scale)
+		}
+		cols[0].Values = append(cols[0].Values, value.NewStringValue(col.Field.Name))
+		cols[1].Values = append(cols[1].Values, value.NewStringValue(fieldType))
+		cols[2].Values = append(cols[2].Values, value.NewBoolValueFromBool(col.Field.Nullable))
+	}
+
+	return table.NewTable(tableName, cols), nil
+}
+
+// DropTable удаляет таблицу или представление
 func (a *App) DropTable(tableName string) error {
+	if _, found := a.views[tableName]; found {
+		return a.DropView(tableName)
+	}
 	if _, found := a.tables[tableName]; !found {
 		return fmt.Errorf("table '%s' doesn't exist", tableName)
 	}
@@ -54,6 +123,32 @@ func (a *App) DropTable(tableName string) error {
 	return nil
 }
 
+// CreateView сохраняет запрос представления. Запрос выполняется сразу, чтобы проверить его,
+// поэтому представление может обращаться только к существующим таблицам и представлениям.
+func (a *App) CreateView(ctx context.Context, viewName string, stmt parser.SelectStmt) error {
+	if strings.TrimSpace(viewName) == "" {
+		return fmt.Errorf("view name should'n be empty")
+	}
+	if err := a.checkNameIsFree(viewName); err != nil {
+		return err
+	}
+	if _, err := a.execute(ctx, stmt, nil, ""); err != nil {
+		return err
+	}
+	a.views[viewName] = stmt
+
+	return nil
+}
+
+func (a *App) DropView(viewName string) error {
+	if _, found := a.views[viewName]; !found {
+		return fmt.Errorf("view '%s' doesn't exist", viewName)
+	}
+	delete(a.views, viewName)
+
+	return nil
+}
+
 func (a *App) Execute(ctx context.Context, query string) (table.Table, error) {
 	stmtScanner := scanner.NewScanner(a.logger)
 	tokens, err := stmtScanner.Scan(strings.NewReader(query))
@@ -61,62 +156,561 @@ func (a *App) Execute(ctx context.Context, query string) (table.Table, error) {
 		return table.Table{}, err
 	}
 
-	stmt, err := parser.MakeSelectStmt(tokens)
+	parsed, err := parser.MakeStmt(tokens)
 	if err != nil {
 		return table.Table{}, err
 	}
-	a.logger.Debug("select stmt made",
-		zap.String("tablename", stmt.Tablename),
-		zap.Bool("all fields", stmt.AllField),
-		zap.String("fields", strings.Join(stmt.Fields, ", ")),
-		zap.String("query", query),
-	)
 
-	t, found := a.tables[stmt.Tablename]
-	if !found {
-		a.logger.Debug("table doesn't exist",
+	switch stmt := parsed.(type) {
+	case parser.SelectStmt:
+		a.logger.Debug("select stmt made",
 			zap.String("tablename", stmt.Tablename),
+			zap.Bool("all fields", stmt.AllField),
+			zap.String("fields", strings.Join(stmt.Fields, ", ")),
 			zap.String("query", query),
 		)
-		return table.Table{}, fmt.Errorf("table '%s' doesn't exist", stmt.Tablename)
-	}
 
-	indexes, err := stmt.Filter.Apply(ctx, t)
-	if err != nil {
-		a.logger.Debug("error when filtering table",
+		return a.execute(ctx, stmt, nil, query)
+	case parser.CreateTableStmt:
+		a.logger.Debug("create table stmt made",
 			zap.String("tablename", stmt.Tablename),
 			zap.String("query", query),
-			zap.Error(err),
 		)
+
+		return a.createTable(ctx, stmt, query)
+	case parser.CreateViewStmt:
+		a.logger.Debug("create view stmt made",
+			zap.String("viewname", stmt.Viewname),
+			zap.String("query", query),
+		)
+		if err = a.CreateView(ctx, stmt.Viewname, stmt.Select); err != nil {
+			return table.Table{}, err
+		}
+
+		return affectedRows(0), nil
+	case parser.DropViewStmt:
+		if err = a.DropView(stmt.Viewname); err != nil {
+			return table.Table{}, err
+		}
+
+		return affectedRows(0), nil
+	case parser.InsertStmt:
+		return a.modify(stmt.Tablename, func(t table.Table) (table.Table, int, error) {
+			ret, err := dml.Insert(ctx, t, stmt.Rows)
+
+			return ret, len(stmt.Rows), err
+		})
+	case parser.UpdateStmt:
+		return a.modify(stmt.Tablename, func(t table.Table) (table.Table, int, error) {
+			indexes, err := a.filter(ctx, t, stmt.Filter, stmt.Subqueries, query)
+			if err != nil {
+				return table.Table{}, 0, err
+			}
+			ret, err := dml.Update(ctx, t, indexes, stmt.Assignments)
+
+			return ret, len(indexes), err
+		})
+	case parser.DeleteStmt:
+		return a.modify(stmt.Tablename, func(t table.Table) (table.Table, int, error) {
+			indexes, err := a.filter(ctx, t, stmt.Filter, stmt.Subqueries, query)
+			if err != nil {
+				return table.Table{}, 0, err
+			}
+			ret, err := dml.Delete(ctx, t, indexes)
+
+			return ret, len(indexes), err
+		})
+	default:
+		return table.Table{}, fmt.Errorf("unsupported statement")
+	}
+}
+
+// createTable сохраняет результат запроса как новую таблицу и возвращает количество записанных строк
+func (a *App) createTable(ctx context.Context, stmt parser.CreateTableStmt, query string) (table.Table, error) {
+	if err := a.checkNameIsFree(stmt.Tablename); err != nil {
+		return table.Table{}, err
+	}
+	res, err := a.execute(ctx, stmt.Select, nil, query)
+	if err != nil {
 		return table.Table{}, err
 	}
 
-	ret, err := t.GetSubTableByIndexes(ctx, indexes)
+	// Значения копируются, чтобы новая таблица не зависела от исходной.
+	// Псевдонимы таблиц запроса не сохраняются в наименованиях полей: 's.country' становится 'country'.
+	qualifiers := tableQualifiers(stmt.Select)
+	names := make(map[string]string, len(res.Columns))
+	cols := make([]table.Column, 0, len(res.Columns))
+	for _, col := range res.Columns {
+		field := col.Field
+		field.Table = ""
+		field.Name = unqualified(field.Name, qualifiers)
+		if prev, found := names[field.Name]; found {
+			if prev == col.Field.Name {
+				return table.Table{}, fmt.Errorf("column '%s' is specified twice", col.Field.Name)
+			}
+
+			return table.Table{}, fmt.Errorf("columns '%s' and '%s' have the same name '%s', specify an alias",
+				prev, col.Field.Name, field.Name)
+		}
+		names[field.Name] = col.Field.Name
+
+		cols = append(cols, table.Column{Field: field, Values: append([]table.Value{}, col.Values...)})
+	}
+
+	created := table.NewTable(stmt.Tablename, cols)
+	if err = a.LoadTable(created); err != nil {
+		return table.Table{}, err
+	}
+
+	return affectedRows(created.RowCount()), nil
+}
+
+// tableQualifiers возвращает псевдонимы и наименования таблиц секций from и join запроса stmt
+func tableQualifiers(stmt parser.SelectStmt) []string {
+	ret := []string{stmt.TableAlias, stmt.Tablename}
+	for _, join := range stmt.Joins {
+		ret = append(ret, join.Alias, join.Tablename)
+	}
+
+	return ret
+}
+
+// unqualified убирает из наименования поля префикс '<псевдоним>.', если псевдоним есть среди qualifiers
+func unqualified(name string, qualifiers []string) string {
+	for _, q := range qualifiers {
+		if q != "" && strings.HasPrefix(name, q+".") {
+			return strings.TrimPrefix(name, q+".")
+		}
+	}
+
+	return name
+}
+
+// modify заменяет таблицу tableName результатом изменения apply и возвращает количество затронутых строк.
+// При ошибке таблица не изменяется.
+func (a *App) modify(tableName string, apply func(t table.Table) (table.Table, int, error)) (table.Table, error) {
+	if _, found := a.views[tableName]; found {
+		return table.Table{}, fmt.Errorf("view '%s' cannot be modified", tableName)
+	}
+	t, err := a.Table(tableName)
+	if err != nil {
+		return table.Table{}, err
+	}
+	modified, count, err := apply(t)
+	if err != nil {
+		return table.Table{}, err
+	}
+	a.tables[tableName] = modified
+
+	return affectedRows(count), nil
+}
+
+// filter выполняет вложенные запросы условия и возвращает строки таблицы, которые ему удовлетворяют
+func (a *App) filter(
+	ctx context.Context,
+	t table.Table,
+	filter table.LogicalOperation,
+	subqueries []parser.Subquery,
+	query string,
+) ([]int, error) {
+	if err := a.executeSubqueries(ctx, subqueries, nil, query); err != nil {
+		return nil, err
+	}
+
+	return filter.Apply(ctx, t)
+}
+
+// executeSubqueries выполняет вложенные запросы условий, результаты которых нужны до применения условий
+func (a *App) executeSubqueries(
+	ctx context.Context,
+	subqueries []parser.Subquery,
+	temp map[string]table.Table,
+	query string,
+) error {
+	for _, sub := range subqueries {
+		res, err := a.execute(ctx, sub.Stmt, temp, query)
+		if err != nil {
+			return err
+		}
+		if err = sub.Result.SetResult(res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// affectedRows формирует результат запроса, который изменяет таблицы
+func affectedRows(count int) table.Table {
+	return table.NewTable("", []table.Column{{
+		Field:  table.Field{Name: "rows", Type: table.FieldTypeInt},
+		Values: []table.Value{value.NewIntValueFromInt64(int64(count))},
+	}})
+}
+
+// execute выполняет разобранный запрос. Вложенные запросы и запросы секции with выполняются до внешнего.
+// temp - результаты запросов секций with, которые используются вместо загруженных таблиц с тем же наименованием.
+func (a *App) execute(
+	ctx context.Context,
+	stmt parser.SelectStmt,
+	temp map[string]table.Table,
+	query string,
+) (table.Table, error) {
+	if len(stmt.With) > 0 {
+		return a.executeWith(ctx, stmt, temp, query)
+	}
+	if len(stmt.SetOperations) > 0 {
+		return a.executeSet(ctx, stmt, temp, query)
+	}
+
+	if err := a.executeSubqueries(ctx, stmt.Subqueries, temp, query); err != nil {
+		return table.Table{}, err
+	}
+
+	t, err := a.source(ctx, stmt, temp, query)
 	if err != nil {
+		a.logger.Debug("error when getting source table",
+			zap.String("tablename", stmt.Tablename),
+			zap.String("query", query),
+			zap.Error(err),
+		)
 		return table.Table{}, err
 	}
-	if stmt.AllField {
-		return ret, nil
+
+	// Все поля таблицы определяются до того, как к ней будут добавлены вычисляемые поля
+	fields := t.ExpandFields(stmt.Fields)
+	qualified := len(stmt.Joins) > 0 || stmt.TableAlias != ""
+	if stmt.AllField && qualified {
+		// Поля с одинаковыми наименованиями из разных таблиц выводятся с псевдонимами
+		fields = t.ExpandFields([]string{"*"})
 	}
 
-	ret, err = t.GetSubTableByFields(stmt.Fields)
+	indexes, err := stmt.Filter.Apply(ctx, t)
 	if err != nil {
-		a.logger.Debug(
-			"error when getting only necessary columns",
+		a.logger.Debug("error when filtering table",
 			zap.String("tablename", stmt.Tablename),
-			zap.String("cols", strings.Join(stmt.Fields, ", ")),
 			zap.String("query", query),
 			zap.Error(err),
 		)
 		return table.Table{}, err
 	}
+
+	if stmt.Grouped() {
+		t, indexes, err = group(ctx, t, indexes, stmt)
+		if err != nil {
+			a.logger.Debug("error when grouping table",
+				zap.String("tablename", stmt.Tablename),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+	}
+
+	// Оконные функции вычисляются по отобранным строкам в отдельные поля, которые можно использовать в выражениях
+	if len(stmt.Windows) > 0 {
+		t, err = window.Apply(ctx, t, indexes, stmt.Windows)
+		if err != nil {
+			a.logger.Debug("error when computing window functions",
+				zap.String("tablename", stmt.Tablename),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+		indexes = t.RowIndexes()
+	}
+
+	// Вычисляемые поля добавляются до сортировки, чтобы по ним можно было упорядочить результат
+	if len(stmt.Computed) > 0 {
+		t, err = compute(ctx, t, indexes, stmt.Computed)
+		if err != nil {
+			a.logger.Debug("error when computing fields",
+				zap.String("tablename", stmt.Tablename),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+		indexes = t.RowIndexes()
+	}
+
+	if len(stmt.OrderBy) > 0 {
+		indexes, err = t.SortRowIndexes(ctx, indexes, stmt.OrderBy)
+		if err != nil {
+			a.logger.Debug("error when sorting table",
+				zap.String("tablename", stmt.Tablename),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+	}
+	// Повторы исключаются по выбранным полям, поэтому limit применяется после этого
+	if stmt.Limit != nil && !stmt.Distinct {
+		indexes = applyLimit(indexes, *stmt.Limit)
+	}
+
+	ret, err := t.GetSubTableByIndexes(ctx, indexes)
+	if err != nil {
+		return table.Table{}, err
+	}
+	if !stmt.AllField || qualified {
+		ret, err = ret.GetSubTableByFields(fields)
+		if err != nil {
+			a.logger.Debug(
+				"error when getting only necessary columns",
+				zap.String("tablename", stmt.Tablename),
+				zap.String("cols", strings.Join(stmt.Fields, ", ")),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+	}
+	if stmt.Distinct {
+		ret, err = distinct(ctx, ret, stmt.Limit)
+		if err != nil {
+			return table.Table{}, err
+		}
+	}
 	a.logger.Debug(
 		"statement executed",
 		zap.String("tablename", stmt.Tablename),
 		zap.String("cols", strings.Join(stmt.Fields, ", ")),
 		zap.String("query", query),
-		zap.Int("row_num", len(ret.Columns[0].Values)),
+		zap.Int("row_num", ret.RowCount()),
 	)
 
 	return ret, nil
 }
+
+// executeWith выполняет запросы секции with по порядку, каждый следующий может использовать результаты предыдущих.
+// Результаты доступны только при выполнении этого запроса и не добавляются в список таблиц.
+func (a *App) executeWith(
+	ctx context.Context,
+	stmt parser.SelectStmt,
+	temp map[string]table.Table,
+	query string,
+) (table.Table, error) {
+	scope := make(map[string]table.Table, len(temp)+len(stmt.With))
+	for name, t := range temp {
+		scope[name] = t
+	}
+	for _, cte := range stmt.With {
+		t, err := a.execute(ctx, cte.Stmt, scope, query)
+		if err != nil {
+			a.logger.Debug("error when executing with",
+				zap.String("tablename", cte.Name),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+		t.Name = cte.Name
+		scope[cte.Name] = t
+	}
+
+	main := stmt
+	main.With = nil
+
+	return a.execute(ctx, main, scope, query)
+}
+
+// executeSet выполняет части запроса и объединяет их результаты слева направо.
+// Сортировка и limit применяются к общему результату.
+func (a *App) executeSet(
+	ctx context.Context,
+	stmt parser.SelectStmt,
+	temp map[string]table.Table,
+	query string,
+) (table.Table, error) {
+	head := stmt
+	head.SetOperations, head.OrderBy, head.Limit = nil, nil, nil
+	ret, err := a.execute(ctx, head, temp, query)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	for _, op := range stmt.SetOperations {
+		right, err := a.execute(ctx, op.Stmt, temp, query)
+		if err != nil {
+			return table.Table{}, err
+		}
+		ret, err = set.Combine(ctx, ret, right, op.Type)
+		if err != nil {
+			a.logger.Debug("error when combining results",
+				zap.String("operation", string(op.Type)),
+				zap.String("query", query),
+				zap.Error(err),
+			)
+			return table.Table{}, err
+		}
+	}
+
+	indexes := ret.RowIndexes()
+	if len(stmt.OrderBy) > 0 {
+		indexes, err = ret.SortRowIndexes(ctx, indexes, stmt.OrderBy)
+		if err != nil {
+			return table.Table{}, err
+		}
+	}
+	if stmt.Limit != nil {
+		indexes = applyLimit(indexes, *stmt.Limit)
+	}
+
+	return ret.GetSubTableByIndexes(ctx, indexes)
+}
+
+// source возвращает таблицу секции from, соединённую с таблицами секций join
+func (a *App) source(
+	ctx context.Context,
+	stmt parser.SelectStmt,
+	temp map[string]table.Table,
+	query string,
+) (table.Table, error) {
+	t, err := a.sourceTable(ctx, stmt.Tablename, stmt.Source, temp, query)
+	if err != nil {
+		return table.Table{}, err
+	}
+	if len(stmt.Joins) == 0 && stmt.TableAlias == "" {
+		return t, nil
+	}
+
+	t = t.Qualify(stmt.Qualifier())
+	for _, j := range stmt.Joins {
+		right, err := a.sourceTable(ctx, j.Tablename, j.Source, temp, query)
+		if err != nil {
+			return table.Table{}, err
+		}
+		t, err = join.Join(ctx, t, right.Qualify(j.Qualifier()), j.Type, j.On)
+		if err != nil {
+			return table.Table{}, err
+		}
+	}
+
+	return t, nil
+}
+
+// sourceTable возвращает результат запроса секции with, загруженную таблицу, результат запроса представления
+// или результат вложенного запроса, указанного вместо таблицы
+func (a *App) sourceTable(
+	ctx context.Context,
+	tableName string,
+	source *parser.SelectStmt,
+	temp map[string]table.Table,
+	query string,
+) (table.Table, error) {
+	if source != nil {
+		return a.execute(ctx, *source, temp, query)
+	}
+	if t, found := temp[tableName]; found {
+		return t, nil
+	}
+	// Представление не видит запросов секции with того запроса, в котором используется
+	if view, found := a.views[tableName]; found {
+		t, err := a.execute(ctx, view, nil, query)
+		if err != nil {
+			return table.Table{}, fmt.Errorf("view '%s': %w", tableName, err)
+		}
+		t.Name = tableName
+
+		return t, nil
+	}
+
+	return a.Table(tableName)
+}
+
+// group вычисляет агрегатные функции по группам строк и отбирает группы по условию having
+func group(ctx context.Context, t table.Table, indexes []int, stmt parser.SelectStmt) (table.Table, []int, error) {
+	aggregates := make([]table.Aggregate, 0, len(stmt.Aggregates)+len(stmt.HiddenAggregates))
+	aggregates = append(aggregates, stmt.Aggregates...)
+	aggregates = append(aggregates, stmt.HiddenAggregates...)
+
+	grouped, err := aggregate.GroupBy(ctx, t, indexes, stmt.GroupBy, aggregates)
+	if err != nil {
+		return table.Table{}, nil, err
+	}
+	if stmt.Having == nil {
+		return grouped, grouped.RowIndexes(), nil
+	}
+
+	indexes, err = stmt.Having.Apply(ctx, grouped)
+	if err != nil {
+		return table.Table{}, nil, err
+	}
+
+	return grouped, indexes, nil
+}
+
+// compute возвращает строки indexes таблицы с добавленными вычисляемыми полями.
+// Вычисляемое поле заменяет поле таблицы с тем же наименованием.
+func compute(ctx context.Context, t table.Table, indexes []int, computed []parser.ComputedField) (table.Table, error) {
+	sub, err := t.GetSubTableByIndexes(ctx, indexes)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	cols := sub.Columns
+	for _, c := range computed {
+		expr, err := expression.Compile(c.Expression, sub)
+		if err != nil {
+			return table.Table{}, err
+		}
+		field, err := expr.Field(sub)
+		if err != nil {
+			return table.Table{}, err
+		}
+		field.Name, field.Table = c.Name, ""
+
+		col := table.Column{Field: field, Values: make([]table.Value, 0, sub.RowCount())}
+		for rowIndex := 0; rowIndex < sub.RowCount(); rowIndex++ {
+			select {
+			case <-ctx.Done():
+				return table.Table{}, ctx.Err()
+			default:
+			}
+
+			val, err := expr.Evaluate(sub, rowIndex)
+			if err != nil {
+				return table.Table{}, err
+			}
+			col.Values = append(col.Values, val)
+		}
+
+		replaced := false
+		for i := range cols {
+			if cols[i].Field.Name == c.Name {
+				cols[i] = col
+				replaced = true
+			}
+		}
+		if !replaced {
+			cols = append(cols, col)
+		}
+	}
+
+	return table.NewTable(sub.Name, cols), nil
+}
+
+// distinct исключает повторяющиеся строки результата и применяет к оставшимся строкам limit
+func distinct(ctx context.Context, t table.Table, limit *parser.Limit) (table.Table, error) {
+	t, err := set.Distinct(ctx, t)
+	if err != nil || limit == nil {
+		return t, err
+	}
+
+	return t.GetSubTableByIndexes(ctx, applyLimit(t.RowIndexes(), *limit))
+}
+
+func applyLimit(indexes []int, limit parser.Limit) []int {
+	if limit.Offset >= len(indexes) {
+		return nil
+	}
+	indexes = indexes[limit.Offset:]
+	if limit.Count < len(indexes) {
+		indexes = indexes[:limit.Count]
+	}
+
+	return indexes
+}
@@ -0,0 +1,284 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+func newTestApp(t *testing.T) *App {
+	regions := []string{"Europe", "Asia", "Europe"}
+	units := []int64{10, 20, 30}
+
+	regionValues := make([]table.Value, 0, len(regions))
+	unitValues := make([]table.Value, 0, len(units))
+	for i := range regions {
+		regionValues = append(regionValues, value.NewStringValue(regions[i]))
+		unitValues = append(unitValues, value.NewIntValueFromInt64(units[i]))
+	}
+
+	a := NewApp(zap.NewNop())
+	err := a.LoadTable(table.NewTable("sales", []table.Column{
+		{Field: table.Field{Name: "region", Type: table.FieldTypeString}, Values: regionValues},
+		{Field: table.Field{Name: "units", Type: table.FieldTypeInt}, Values: unitValues},
+	}))
+	assert.NoError(t, err)
+
+	return a
+}
+
+// execute выполняет запросы по порядку и возвращает строки результата последнего из них
+func execute(a *App, queries ...string) ([][]string, error) {
+	var res table.Table
+	for _, query := range queries {
+		var err error
+		if res, err = a.Execute(context.Background(), query); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := make([][]string, 0, res.RowCount())
+	for rowIndex := 0; rowIndex < res.RowCount(); rowIndex++ {
+		row := make([]string, 0, len(res.Columns))
+		for _, col := range res.Columns {
+			row = append(row, col.Values[rowIndex].String())
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func tableList(a *App) []string {
+	ret := a.TableList()
+	sort.Strings(ret)
+
+	return ret
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name       string
+		queries    []string
+		want       [][]string
+		wantErr    string
+		wantTables []string
+	}{
+		{
+			name: "with hides loaded table",
+			queries: []string{
+				"with sales as (select region from sales where units > 15) select * from sales;",
+			},
+			want:       [][]string{{"Asia"}, {"Europe"}},
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "with is not registered",
+			queries: []string{
+				"with big as (select region from sales where units > 15) select * from big;",
+				"select * from big;",
+			},
+			wantErr:    "table 'big' doesn't exist",
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "loaded table is visible after with",
+			queries: []string{
+				"with sales as (select region from sales where units > 15) select * from sales;",
+				"select units from sales;",
+			},
+			want:       [][]string{{"10"}, {"20"}, {"30"}},
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "create table",
+			queries: []string{
+				"create table eu as select units from sales where region = 'Europe';",
+			},
+			want:       [][]string{{"2"}},
+			wantTables: []string{"eu", "sales"},
+		},
+		{
+			name: "select from created table",
+			queries: []string{
+				"create table eu as select units from sales where region = 'Europe';",
+				"select * from eu;",
+			},
+			want:       [][]string{{"10"}, {"30"}},
+			wantTables: []string{"eu", "sales"},
+		},
+		{
+			name: "create table with name of loaded table",
+			queries: []string{
+				"create table sales as select * from sales;",
+			},
+			wantErr:    "table 'sales' has already exist",
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "create table with repeated fields",
+			queries: []string{
+				"create table twice as select units, units from sales;",
+			},
+			wantErr:    "column 'units' is specified twice",
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "select from view",
+			queries: []string{
+				"create view big as select region from sales where units > 15;",
+				"select * from big;",
+			},
+			want:       [][]string{{"Asia"}, {"Europe"}},
+			wantTables: []string{"big (view)", "sales"},
+		},
+		{
+			name: "create table with name of view",
+			queries: []string{
+				"create view eu as select * from sales where region = 'Europe';",
+				"create table eu as select * from sales;",
+			},
+			wantErr:    "view 'eu' has already exist",
+			wantTables: []string{"eu (view)", "sales"},
+		},
+		{
+			name: "drop view",
+			queries: []string{
+				"create view big as select region from sales where units > 15;",
+				"drop view big;",
+				"select * from big;",
+			},
+			wantErr:    "table 'big' doesn't exist",
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "view is evaluated after insert",
+			queries: []string{
+				"create view big as select region from sales where units > 15;",
+				"insert into sales values ('Africa', 40);",
+				"select * from big;",
+			},
+			want:       [][]string{{"Asia"}, {"Europe"}, {"Africa"}},
+			wantTables: []string{"big (view)", "sales"},
+		},
+		{
+			name: "view cannot be modified",
+			queries: []string{
+				"create view big as select region from sales where units > 15;",
+				"delete from big;",
+			},
+			wantErr:    "view 'big' cannot be modified",
+			wantTables: []string{"big (view)", "sales"},
+		},
+		{
+			name: "insert",
+			queries: []string{
+				"insert into sales values ('Africa', 40), ('Asia', 50);",
+			},
+			want:       [][]string{{"2"}},
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "update",
+			queries: []string{
+				"update sales set units = units * 2 where region = 'Europe';",
+				"select units from sales;",
+			},
+			want:       [][]string{{"20"}, {"20"}, {"60"}},
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "delete",
+			queries: []string{
+				"delete from sales where units in (select units from sales where region = 'Europe');",
+			},
+			want:       [][]string{{"2"}},
+			wantTables: []string{"sales"},
+		},
+		{
+			name: "failed insert",
+			queries: []string{
+				"insert into sales values ('Africa', 40), ('Asia', null);",
+			},
+			wantErr:    "field 'units' cannot be null",
+			wantTables: []string{"sales"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t)
+			got, err := execute(a, tt.queries...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantTables, tableList(a))
+		})
+	}
+}
+
+func TestExecute_FailedStatementKeepsTable(t *testing.T) {
+	queries := []string{
+		"insert into sales values ('Africa', 40), ('Asia', null);",
+		// Ошибка возникает на последней строке, после изменения предыдущих
+		"update sales set units = 60 / (units - 30);",
+		"delete from sales where units > (select region from sales);",
+	}
+
+	for _, query := range queries {
+		a := newTestApp(t)
+		_, err := a.Execute(context.Background(), query)
+		assert.Error(t, err, query)
+
+		got, err := execute(a, "select * from sales;")
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{{"Europe", "10"}, {"Asia", "20"}, {"Europe", "30"}}, got, query)
+	}
+}
+
+func TestExecute_CreateTableFromJoin(t *testing.T) {
+	a := newTestApp(t)
+	_, err := execute(a, "create table managers as select region, units as manager from sales where units < 25;")
+	assert.NoError(t, err)
+
+	_, err = execute(a, "create table staff as select s.units, m.manager from sales s join managers m on s.region = m.region;")
+	assert.NoError(t, err)
+	staff, err := a.Table("staff")
+	assert.NoError(t, err)
+	names := make([]string, 0, len(staff.Columns))
+	for _, col := range staff.Columns {
+		names = append(names, col.Field.Name)
+	}
+	assert.Equal(t, []string{"units", "manager"}, names)
+
+	_, err = execute(a, "create table twice as select s.region, m.region from sales s join managers m on s.region = m.region;")
+	assert.EqualError(t, err, "columns 's.region' and 'm.region' have the same name 'region', specify an alias")
+
+	got, err := execute(a,
+		"create table aliased as select s.region, m.region as managed from sales s join managers m on s.region = m.region;",
+		"select * from aliased;")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Europe", "Europe"}, {"Asia", "Asia"}, {"Europe", "Europe"}}, got)
+}
+
+func TestExecute_QuotedReservedWords(t *testing.T) {
+	a := newTestApp(t)
+	got, err := execute(a,
+		`create table t as select region as "values", units as "set" from sales;`,
+		`update t set "set" = "set" + 1 where "values" = 'Asia';`,
+		`select "values", "set" from t where "set" > 20 order by "set";`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Asia", "21"}, {"Europe", "30"}}, got)
+
+	_, err = execute(a, "select set from t;")
+	assert.Error(t, err)
+}
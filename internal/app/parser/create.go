@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+)
+
+// table и view не являются ключевыми словами, чтобы их можно было использовать как наименования таблиц
+const (
+	objectTable = "table"
+	objectView  = "view"
+)
+
+// CreateTableStmt - создание таблицы из результата запроса: create table name as select ...
+type CreateTableStmt struct {
+	Tablename string
+	Select    SelectStmt
+}
+
+// CreateViewStmt - создание представления: create view name as select ...
+// Запрос представления выполняется при каждом обращении к нему.
+type CreateViewStmt struct {
+	Viewname string
+	Select   SelectStmt
+}
+
+// DropViewStmt - удаление представления: drop view name
+type DropViewStmt struct {
+	Viewname string
+}
+
+func MakeCreateTableStmt(tokens []scanner.Token) (CreateTableStmt, error) {
+	name, stmt, err := makeCreateStmt(tokens, objectTable)
+	if err != nil {
+		return CreateTableStmt{}, err
+	}
+
+	return CreateTableStmt{Tablename: name, Select: stmt}, nil
+}
+
+func MakeCreateViewStmt(tokens []scanner.Token) (CreateViewStmt, error) {
+	name, stmt, err := makeCreateStmt(tokens, objectView)
+	if err != nil {
+		return CreateViewStmt{}, err
+	}
+
+	return CreateViewStmt{Viewname: name, Select: stmt}, nil
+}
+
+func MakeDropViewStmt(tokens []scanner.Token) (DropViewStmt, error) {
+	if len(tokens) != 3 || !isKeyword(tokens[0], KeywordDrop) || !isObject(tokens[1], objectView) ||
+		tokens[2].Type() != scanner.TokenTypeID {
+		return DropViewStmt{}, fmt.Errorf("drop view should be in format drop view name")
+	}
+
+	return DropViewStmt{Viewname: tokens[2].Value().(string)}, nil
+}
+
+// makeCreateStmt разбирает запрос create <object> name as select ...
+func makeCreateStmt(tokens []scanner.Token, object string) (string, SelectStmt, error) {
+	formatErr := fmt.Errorf("create %s should be in format create %s name as select ...", object, object)
+	if len(tokens) < 4 || !isKeyword(tokens[0], KeywordCreate) || !isObject(tokens[1], object) ||
+		tokens[2].Type() != scanner.TokenTypeID || !isKeyword(tokens[3], KeywordAs) {
+		return "", SelectStmt{}, formatErr
+	}
+
+	query := tokens[4:]
+	// Запрос можно заключить в скобки
+	if isSubqueryStart(query, 0) && subqueryEnd(query, 0) == len(query)-1 {
+		query = query[1 : len(query)-1]
+	}
+	if len(query) == 0 {
+		return "", SelectStmt{}, formatErr
+	}
+	stmt, err := MakeSelectStmt(query)
+	if err != nil {
+		return "", SelectStmt{}, err
+	}
+
+	return tokens[2].Value().(string), stmt, nil
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+)
+
+// InsertStmt - добавление строк в таблицу: insert into name values (...), (...)
+type InsertStmt struct {
+	Tablename string
+	Rows      [][]table.Expression
+}
+
+// UpdateStmt - изменение полей строк, которые удовлетворяют условию: update name set field = expr, ... where ...
+type UpdateStmt struct {
+	Tablename   string
+	Assignments []table.Assignment
+	Filter      table.LogicalOperation
+	// Subqueries - вложенные запросы условия, которые выполняются до его применения, как и в SelectStmt
+	Subqueries []Subquery
+}
+
+// DeleteStmt - удаление строк, которые удовлетворяют условию: delete from name where ...
+type DeleteStmt struct {
+	Tablename  string
+	Filter     table.LogicalOperation
+	Subqueries []Subquery
+}
+
+func MakeInsertStmt(tokens []scanner.Token) (InsertStmt, error) {
+	if len(tokens) < 4 || !isKeyword(tokens[0], KeywordInsert) || !isKeyword(tokens[1], KeywordInto) ||
+		tokens[2].Type() != scanner.TokenTypeID || !isKeyword(tokens[3], KeywordValues) {
+		return InsertStmt{}, fmt.Errorf("insert should be in format insert into name values (...)")
+	}
+
+	stmt := InsertStmt{Tablename: tokens[2].Value().(string)}
+	// Начало и конец каждой строки значений отмечены в списке токенов
+	for i := 4; i < len(tokens); i++ {
+		if tokens[i].Type() != scanner.TokenTypeOpenCurlyBracket {
+			return InsertStmt{}, fmt.Errorf("values should be enclosed in brackets")
+		}
+		end := i + 1
+		for end < len(tokens) && tokens[end].Type() != scanner.TokenTypeClosedCurlyBracket {
+			end++
+		}
+		if end == len(tokens) {
+			return InsertStmt{}, fmt.Errorf("values should be closed by bracket")
+		}
+		row, err := makeExpressions(tokens[i+1 : end])
+		if err != nil {
+			return InsertStmt{}, err
+		}
+		stmt.Rows = append(stmt.Rows, row)
+		i = end
+	}
+	if len(stmt.Rows) == 0 {
+		return InsertStmt{}, fmt.Errorf("values should be specified after values")
+	}
+
+	return stmt, nil
+}
+
+func MakeUpdateStmt(tokens []scanner.Token) (UpdateStmt, error) {
+	if len(tokens) < 4 || !isKeyword(tokens[0], KeywordUpdate) || tokens[1].Type() != scanner.TokenTypeID ||
+		!isKeyword(tokens[2], KeywordSet) {
+		return UpdateStmt{}, fmt.Errorf("update should be in format update name set field = expr, ... [where ...]")
+	}
+
+	where := len(tokens)
+	for i := 3; i < len(tokens); i++ {
+		if isKeyword(tokens[i], KeywordWhere) {
+			where = i
+
+			break
+		}
+	}
+	exprs, err := makeExpressions(tokens[3:where])
+	if err != nil {
+		return UpdateStmt{}, err
+	}
+	assignments := make([]table.Assignment, 0, len(exprs))
+	for _, expr := range exprs {
+		compare, valid := expr.(expression.CompareExpression)
+		if !valid || compare.Type != table.CompareOperationTypeEqual {
+			return UpdateStmt{}, fmt.Errorf("set section should be in format field = expr, ...")
+		}
+		column, valid := compare.Left.(expression.ColumnExpression)
+		if !valid {
+			return UpdateStmt{}, fmt.Errorf("set section should be in format field = expr, ...")
+		}
+		assignments = append(assignments, table.Assignment{ColumnName: column.ColumnName, Expression: compare.Right})
+	}
+
+	filter, err := makeFilter(KeywordUpdate, append([]scanner.Token{tokens[1]}, tokens[where:]...))
+	if err != nil {
+		return UpdateStmt{}, err
+	}
+
+	return UpdateStmt{
+		Tablename:   filter.Tablename,
+		Assignments: assignments,
+		Filter:      filter.Filter,
+		Subqueries:  filter.Subqueries,
+	}, nil
+}
+
+func MakeDeleteStmt(tokens []scanner.Token) (DeleteStmt, error) {
+	if len(tokens) < 3 || !isKeyword(tokens[0], KeywordDelete) || !isKeyword(tokens[1], KeywordFrom) {
+		return DeleteStmt{}, fmt.Errorf("delete should be in format delete from name [where ...]")
+	}
+
+	filter, err := makeFilter(KeywordDelete, tokens[2:])
+	if err != nil {
+		return DeleteStmt{}, err
+	}
+
+	return DeleteStmt{
+		Tablename:  filter.Tablename,
+		Filter:     filter.Filter,
+		Subqueries: filter.Subqueries,
+	}, nil
+}
+
+// makeFilter разбирает таблицу и условие запроса keyword так же, как секции запроса select * from ...
+func makeFilter(keyword string, tokens []scanner.Token) (SelectStmt, error) {
+	query := append([]scanner.Token{
+		scanner.NewToken(KeywordSelect, scanner.TokenTypeKeyword),
+		scanner.NewToken(allFieldsSign, scanner.TokenTypeID),
+		scanner.NewToken(KeywordFrom, scanner.TokenTypeKeyword),
+	}, tokens...)
+	stmt, err := makeSimpleSelectStmt(query)
+	if err != nil {
+		return SelectStmt{}, err
+	}
+	if stmt.Source != nil || stmt.TableAlias != "" || len(stmt.Joins) > 0 || stmt.Grouped() ||
+		stmt.Having != nil || len(stmt.OrderBy) > 0 || stmt.Limit != nil {
+		return SelectStmt{}, fmt.Errorf("%s supports only table name and where section", keyword)
+	}
+
+	return stmt, nil
+}
+
+// makeExpressions разбирает выражения, перечисленные через запятую. Агрегатные и оконные функции,
+// вложенные запросы и псевдонимы в них не допускаются.
+func makeExpressions(tokens []scanner.Token) ([]table.Expression, error) {
+	b := selectStmtBuilder{lastKeyword: KeywordSelect}
+	for _, token := range tokens {
+		if token.Type() == scanner.TokenTypeKeyword || token.Type() == scanner.TokenTypeOpenCurlyBracket {
+			return nil, fmt.Errorf("%v is not allowed in expression", token.Value())
+		}
+		if err := b.appendSelect(token); err != nil {
+			return nil, err
+		}
+	}
+	if len(b.items) == 0 {
+		return nil, fmt.Errorf("expression should be specified")
+	}
+
+	ret := make([]table.Expression, 0, len(b.items))
+	for _, item := range b.items {
+		if item.aggregate != nil {
+			return nil, fmt.Errorf("aggregate function %s is not allowed here", item.aggregate.Type)
+		}
+		ret = append(ret, item.expr)
+	}
+	if len(b.nestedAggregates) > 0 {
+		return nil, fmt.Errorf("aggregate function %s is not allowed here", b.nestedAggregates[0].Type)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,309 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+// ComputedField - поле результата, значение которого вычисляется по выражению
+type ComputedField struct {
+	Name       string
+	Expression table.Expression
+}
+
+// selectItem - выражение секции select
+type selectItem struct {
+	expr  table.Expression
+	alias string
+	// aggregate заполнено, если выражение - вызов агрегатной функции
+	aggregate *table.Aggregate
+}
+
+// appendSelect собирает выражения секции select из токенов в обратной польской записи
+func (b *selectStmtBuilder) appendSelect(token scanner.Token) error {
+	if b.expectAlias {
+		if token.Type() != scanner.TokenTypeID {
+			return fmt.Errorf("alias should be specified after as")
+		}
+		b.items[len(b.items)-1].alias = token.Value().(string)
+		b.expectAlias = false
+
+		return nil
+	}
+
+	switch token.Type() {
+	case scanner.TokenTypeID:
+		b.items = append(b.items, selectItem{
+			expr: expression.ColumnExpression{ColumnName: token.Value().(string)},
+		})
+	case scanner.TokenTypeString, scanner.TokenTypeNumber, scanner.TokenTypeBool, scanner.TokenTypeNull:
+		val, err := makeConst(token)
+		if err != nil {
+			return err
+		}
+		b.items = append(b.items, selectItem{expr: expression.ConstExpression{Value: val}})
+	case scanner.TokenTypeOpNegate:
+		operands, err := b.popOperands(token, 1)
+		if err != nil {
+			return err
+		}
+		b.items = append(b.items, selectItem{expr: expression.NegateExpression{Operand: operands[0]}})
+	case scanner.TokenTypeOpPlus, scanner.TokenTypeOpMinus, scanner.TokenTypeOpMultiply, scanner.TokenTypeOpDivide:
+		operands, err := b.popOperands(token, 2)
+		if err != nil {
+			return err
+		}
+		b.items = append(b.items, selectItem{expr: expression.ArithmeticExpression{
+			Type:  table.ArithmeticOperationType(token.Value().(string)),
+			Left:  operands[0],
+			Right: operands[1],
+		}})
+	case scanner.TokenTypeFunction:
+		return b.appendSelectFunction(token)
+	default:
+		return b.appendSelectCondition(token)
+	}
+
+	return nil
+}
+
+// appendSelectCondition собирает условия, которые используются в выражении case
+func (b *selectStmtBuilder) appendSelectCondition(token scanner.Token) error {
+	if op, found := parseCompareOperation(token.Type()); found {
+		operands, err := b.popOperands(token, 2)
+		if err != nil {
+			return err
+		}
+		b.items = append(b.items, selectItem{expr: expression.CompareExpression{
+			Type:  op,
+			Left:  operands[0],
+			Right: operands[1],
+		}})
+
+		return nil
+	}
+
+	switch token.Type() {
+	case scanner.TokenTypeOpAnd, scanner.TokenTypeOpOr:
+		operands, err := b.popOperands(token, 2)
+		if err != nil {
+			return err
+		}
+		b.items = append(b.items, selectItem{expr: expression.LogicalExpression{
+			Type:  table.LogicalOperationType(token.Value().(string)),
+			Left:  operands[0],
+			Right: operands[1],
+		}})
+	case scanner.TokenTypeOpNot:
+		operands, err := b.popOperands(token, 1)
+		if err != nil {
+			return err
+		}
+		b.items = append(b.items, selectItem{expr: expression.NotExpression{Operand: operands[0]}})
+	case scanner.TokenTypeOpIs, scanner.TokenTypeOpIsNot:
+		operands, err := b.popOperands(token, 2)
+		if err != nil {
+			return err
+		}
+		if c, valid := operands[1].(expression.ConstExpression); !valid || c.Value.Value() != nil {
+			return fmt.Errorf("is should be followed by null")
+		}
+		b.items = append(b.items, selectItem{expr: expression.IsNullExpression{
+			Operand: operands[0],
+			Not:     token.Type() == scanner.TokenTypeOpIsNot,
+		}})
+	default:
+		return fmt.Errorf("invalid format of select stmt")
+	}
+
+	return nil
+}
+
+func (b *selectStmtBuilder) appendSelectFunction(token scanner.Token) error {
+	name := token.Value().(string)
+	if _, err := parseAggregateType(name); err == nil {
+		if len(b.items) == 0 || token.Args() != 1 {
+			return fmt.Errorf("function %s requires an argument", token.Value())
+		}
+		column, valid := b.items[len(b.items)-1].expr.(expression.ColumnExpression)
+		if !valid || b.items[len(b.items)-1].aggregate != nil {
+			return fmt.Errorf("argument of function %s should be a field", token.Value())
+		}
+		a, err := makeAggregate(token, column.ColumnName)
+		if err != nil {
+			return err
+		}
+		b.items[len(b.items)-1] = selectItem{
+			expr:      expression.ColumnExpression{ColumnName: a.Name()},
+			aggregate: &a,
+		}
+
+		return nil
+	}
+
+	args, err := b.popOperands(token, token.Args())
+	if err != nil {
+		return err
+	}
+
+	expr, err := makeFunction(token, args)
+	if err != nil {
+		return err
+	}
+	b.items = append(b.items, selectItem{expr: expr})
+
+	return nil
+}
+
+// makeFunction строит вызов скалярной функции, case или cast
+func makeFunction(token scanner.Token, args []table.Expression) (table.Expression, error) {
+	name := token.Value().(string)
+	if token.Distinct() {
+		return nil, fmt.Errorf("distinct is allowed only in aggregate functions")
+	}
+
+	switch name {
+	case scanner.KeywordCase:
+		return expression.NewCaseExpression(args)
+	case scanner.KeywordCast:
+		return makeCast(args)
+	}
+
+	return expression.NewFunctionExpression(name, args)
+}
+
+// makeCast использует наименование типа, указанное после as, как второй аргумент
+func makeCast(args []table.Expression) (table.Expression, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("cast should be in format cast(value as type)")
+	}
+	typeName, valid := args[1].(expression.ColumnExpression)
+	if !valid {
+		return nil, fmt.Errorf("cast should be in format cast(value as type)")
+	}
+
+	return expression.NewCastExpression(args[0], typeName.ColumnName)
+}
+
+// popOperands извлекает операнды операции, агрегатные функции в их составе вычисляются без вывода в результат
+func (b *selectStmtBuilder) popOperands(token scanner.Token, count int) ([]table.Expression, error) {
+	if len(b.items) < count {
+		return nil, fmt.Errorf("operation %s requires %d operands", token.Value(), count)
+	}
+
+	ret := make([]table.Expression, 0, count)
+	for _, item := range b.items[len(b.items)-count:] {
+		if item.alias != "" {
+			return nil, fmt.Errorf("alias '%s' should be specified at the end of expression", item.alias)
+		}
+		if column, valid := item.expr.(expression.ColumnExpression); valid && isAllFields(column.ColumnName) {
+			return nil, fmt.Errorf("'%s' cannot be used in expression", column.ColumnName)
+		}
+		if item.aggregate != nil {
+			b.nestedAggregates = append(b.nestedAggregates, *item.aggregate)
+		}
+		ret = append(ret, item.expr)
+	}
+	b.items = b.items[:len(b.items)-count]
+
+	return ret, nil
+}
+
+// makeSelectFields возвращает наименования полей результата в порядке выражений секции select
+// и отделяет от полей таблицы агрегатные функции и вычисляемые поля
+func (b *selectStmtBuilder) makeSelectFields() ([]string, []ComputedField) {
+	var fields []string
+	var computed []ComputedField
+	var hidden []table.Aggregate
+
+	for _, item := range b.items {
+		name := item.alias
+		if name == "" {
+			name = item.expr.String()
+		}
+		fields = append(fields, name)
+
+		_, isColumn := item.expr.(expression.ColumnExpression)
+		switch {
+		case item.aggregate != nil && item.alias == "":
+			if !containsAggregate(b.aggregates, *item.aggregate) {
+				b.aggregates = append(b.aggregates, *item.aggregate)
+			}
+		case !isColumn || item.aggregate != nil || item.alias != "":
+			computed = append(computed, ComputedField{Name: name, Expression: item.expr})
+			if item.aggregate != nil {
+				hidden = append(hidden, *item.aggregate)
+			}
+		}
+	}
+
+	for _, a := range append(hidden, b.nestedAggregates...) {
+		b.addHiddenAggregate(a)
+	}
+	// Функции из having и order by могли быть добавлены до того, как стало известно, что они выводятся в select
+	hiddenAggregates := b.hiddenAggregates[:0]
+	for _, a := range b.hiddenAggregates {
+		if !containsAggregate(b.aggregates, a) {
+			hiddenAggregates = append(hiddenAggregates, a)
+		}
+	}
+	b.hiddenAggregates = hiddenAggregates
+	if len(b.hiddenAggregates) == 0 {
+		b.hiddenAggregates = nil
+	}
+
+	return fields, computed
+}
+
+// isAllFields проверяет, что наименование обозначает все поля таблицы: '*' или 't.*'
+func isAllFields(name string) bool {
+	return name == allFieldsSign || strings.HasSuffix(name, "."+allFieldsSign)
+}
+
+func containsAggregate(aggregates []table.Aggregate, a table.Aggregate) bool {
+	for _, existing := range aggregates {
+		if existing == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+// makeConst преобразует литерал запроса в значение. Числа без дробной части становятся int,
+// остальные - decimal с числом знаков, указанным в запросе, чтобы вычисления с ними были точными
+func makeConst(token scanner.Token) (table.Value, error) {
+	switch token.Type() {
+	case scanner.TokenTypeString:
+		return value.NewStringValue(token.Value().(string)), nil
+	case scanner.TokenTypeBool:
+		return value.NewBoolValueFromBool(token.Value().(bool)), nil
+	case scanner.TokenTypeNull:
+		return value.NewNullValue(), nil
+	case scanner.TokenTypeNumber:
+		switch n := token.Value().(type) {
+		case int64:
+			return value.NewIntValueFromInt64(n), nil
+		case float64:
+			if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+				return value.NewIntValueFromInt64(int64(n)), nil
+			}
+			literal := strconv.FormatFloat(n, 'f', -1, 64)
+			scale := len(literal) - strings.Index(literal, ".") - 1
+			if scale > value.MaxDecimalScale {
+				return value.NewNumberValueFromFloat(n), nil
+			}
+
+			return value.NewDecimalValue(literal, scale)
+		}
+	}
+
+	return nil, fmt.Errorf("invalid value: '%v'", token.Value())
+}
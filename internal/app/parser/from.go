@@ -5,71 +5,398 @@ import (
 
 	"github.com/stepan2volkov/csvdb/internal/app/scanner"
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
 	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
 )
 
+// operand - операнд условия: токен запроса, выражение из нескольких токенов или условие
+type operand struct {
+	token scanner.Token
+	// expr - выражение операнда, если он составлен из нескольких токенов.
+	// Для условия содержит его запись в виде выражения, если она возможна, чтобы условие можно было использовать в case
+	expr table.Expression
+	cond table.LogicalOperation
+}
+
+func (o operand) isToken() bool {
+	return o.expr == nil && o.cond == nil
+}
+
+// expression возвращает операнд в виде выражения
+func (o operand) expression() (table.Expression, error) {
+	if o.expr != nil {
+		return o.expr, nil
+	}
+	if o.cond != nil {
+		return nil, fmt.Errorf("invalid where format")
+	}
+	switch o.token.Type() {
+	case scanner.TokenTypeID:
+		return expression.ColumnExpression{ColumnName: o.token.Value().(string)}, nil
+	case scanner.TokenTypeString, scanner.TokenTypeNumber, scanner.TokenTypeBool, scanner.TokenTypeNull:
+		val, err := makeConst(o.token)
+		if err != nil {
+			return nil, err
+		}
+
+		return expression.ConstExpression{Value: val}, nil
+	}
+
+	return nil, fmt.Errorf("invalid where format")
+}
+
 func makeWhere(tokens []scanner.Token) (table.LogicalOperation, error) {
-	var ret []table.LogicalOperation
-	tokenQueue := make([]scanner.Token, 0, len(tokens))
+	operands := make([]operand, 0, len(tokens))
 
 	for _, token := range tokens {
-		switch token.Type() {
-		case scanner.TokenTypeOpMore, scanner.TokenTypeOpLess, scanner.TokenTypeOpEqual:
+		var err error
+		if operands, err = appendWhere(operands, token); err != nil {
+			return nil, err
+		}
+	}
+	operands = dropUnclosedBrackets(operands)
+	if len(operands) != 1 || operands[0].cond == nil {
+		return nil, fmt.Errorf("invalid where format")
+	}
 
-			val, id := tokenQueue[len(tokenQueue)-1], tokenQueue[len(tokenQueue)-2]
-			tokenQueue = tokenQueue[:len(tokenQueue)-2]
-			if id.Type() != scanner.TokenTypeID {
-				val, id = id, val
-			}
-			if id.Type() != scanner.TokenTypeID ||
-				(val.Type() != scanner.TokenTypeString && val.Type() != scanner.TokenTypeNumber) {
-				return nil, fmt.Errorf("invalid where format")
-			}
-			var op table.CompareOperationType
-
-			switch token.Type() {
-			case scanner.TokenTypeOpMore:
-				op = table.CompareOperationTypeMore
-			case scanner.TokenTypeOpLess:
-				op = table.CompareOperationTypeLess
-			case scanner.TokenTypeOpEqual:
-				op = table.CompareOperationTypeEqual
-			}
+	return operands[0].cond, nil
+}
 
-			ret = append(ret, operation.DummyValueOperation{
-				CompareOperation: table.CompareValueOperation{
-					ColumnName: id.Value().(string),
-					Type:       op,
-					Val:        val.Value(),
-				},
-			})
-		case scanner.TokenTypeOpAnd:
-			if len(ret) < 2 {
-				return nil, fmt.Errorf("invalid where format")
-			}
-			arg1, arg2 := ret[len(ret)-1], ret[len(ret)-2]
-			ret = ret[:len(ret)-2]
-			ret = append(ret, operation.AndOperation{
-				Left:  arg1,
-				Right: arg2,
-			})
-		case scanner.TokenTypeOpOr:
-			if len(ret) < 2 {
-				return nil, fmt.Errorf("invalid where format")
+// appendWhere применяет токен к операндам в конце стека
+func appendWhere(operands []operand, token scanner.Token) ([]operand, error) {
+	if token.Type() != scanner.TokenTypeOpIn && !isOperandToken(token) {
+		operands = dropUnclosedBrackets(operands)
+	}
+	if op, found := parseCompareOperation(token.Type()); found {
+		if len(operands) < 2 {
+			return nil, fmt.Errorf("invalid where format")
+		}
+		compare, err := makeCompare(op, operands[len(operands)-2], operands[len(operands)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		return append(operands[:len(operands)-2], compare), nil
+	}
+
+	switch token.Type() {
+	case scanner.TokenTypeOpIn, scanner.TokenTypeOpBetweenAnd, scanner.TokenTypeOpIs, scanner.TokenTypeOpIsNot:
+		return makePredicate(token.Type(), operands)
+	case scanner.TokenTypeOpBetween:
+		return nil, fmt.Errorf("between should be followed by and")
+	case scanner.TokenTypeOpAnd, scanner.TokenTypeOpOr, scanner.TokenTypeOpNot:
+		return makeLogical(token.Type(), operands)
+	case scanner.TokenTypeOpNegate, scanner.TokenTypeOpPlus, scanner.TokenTypeOpMinus,
+		scanner.TokenTypeOpMultiply, scanner.TokenTypeOpDivide, scanner.TokenTypeFunction:
+		return makeWhereExpression(token, operands)
+	}
+
+	return append(operands, operand{token: token}), nil
+}
+
+func isOperandToken(token scanner.Token) bool {
+	switch token.Type() {
+	case scanner.TokenTypeID, scanner.TokenTypeNull, scanner.TokenTypeOpenCurlyBracket, scanner.TokenTypeSubquery:
+		return true
+	}
+
+	return isValueToken(token)
+}
+
+// dropUnclosedBrackets убирает скобки, которые не были закрыты в запросе: они не меняют смысла условия
+func dropUnclosedBrackets(operands []operand) []operand {
+	for len(operands) > 0 && operands[len(operands)-1].isToken() &&
+		operands[len(operands)-1].token.Type() == scanner.TokenTypeOpenCurlyBracket {
+		operands = operands[:len(operands)-1]
+	}
+
+	return operands
+}
+
+// makeCompare сравнивает поле со значением или, если операнды - поля или выражения, их значения между собой
+func makeCompare(op table.CompareOperationType, left, right operand) (operand, error) {
+	var ret operand
+	leftExpr, leftErr := left.expression()
+	rightExpr, rightErr := right.expression()
+	if leftErr == nil && rightErr == nil {
+		ret.expr = expression.CompareExpression{Type: op, Left: leftExpr, Right: rightExpr}
+	}
+
+	isColumn := func(o operand) bool {
+		return o.isToken() && o.token.Type() == scanner.TokenTypeID
+	}
+	isValue := func(o operand) bool {
+		return o.isToken() && isValueToken(o.token)
+	}
+	if isColumn(left) && isValue(right) || isValue(left) && isColumn(right) {
+		cond, err := makeCompareOperation(op, left.token, right.token)
+		ret.cond = cond
+
+		return ret, err
+	}
+	if ret.expr == nil {
+		return operand{}, fmt.Errorf("invalid where format")
+	}
+	ret.cond = operation.NewExpressionOperation(ret.expr)
+
+	return ret, nil
+}
+
+func makeLogical(tokenType scanner.TokenType, operands []operand) ([]operand, error) {
+	if tokenType == scanner.TokenTypeOpNot {
+		if len(operands) < 1 || operands[len(operands)-1].cond == nil {
+			return nil, fmt.Errorf("invalid where format")
+		}
+		arg := operands[len(operands)-1]
+		ret := operand{cond: operation.NotOperation{Operation: arg.cond}}
+		if arg.expr != nil {
+			ret.expr = expression.NotExpression{Operand: arg.expr}
+		}
+		operands[len(operands)-1] = ret
+
+		return operands, nil
+	}
+
+	if len(operands) < 2 || operands[len(operands)-2].cond == nil || operands[len(operands)-1].cond == nil {
+		return nil, fmt.Errorf("invalid where format")
+	}
+	left, right := operands[len(operands)-2], operands[len(operands)-1]
+	ret := operand{}
+	logicalType := table.LogicalOperationTypeAnd
+	if tokenType == scanner.TokenTypeOpAnd {
+		ret.cond = operation.AndOperation{Left: right.cond, Right: left.cond}
+	} else {
+		logicalType = table.LogicalOperationTypeOr
+		ret.cond = operation.OrOperation{Left: right.cond, Right: left.cond}
+	}
+	if left.expr != nil && right.expr != nil {
+		ret.expr = expression.LogicalExpression{Type: logicalType, Left: left.expr, Right: right.expr}
+	}
+
+	return append(operands[:len(operands)-2], ret), nil
+}
+
+// makeWhereExpression строит вычисляемый операнд: смену знака, арифметическую операцию или вызов функции
+func makeWhereExpression(token scanner.Token, operands []operand) ([]operand, error) {
+	count := 2
+	switch token.Type() {
+	case scanner.TokenTypeOpNegate:
+		count = 1
+	case scanner.TokenTypeFunction:
+		if _, err := parseAggregateType(token.Value().(string)); err == nil {
+			return nil, fmt.Errorf("function %s is not allowed here", token.Value())
+		}
+		count = token.Args()
+	}
+	if len(operands) < count {
+		return nil, fmt.Errorf("operation %s requires %d operands", token.Value(), count)
+	}
+
+	// Смена знака числа остаётся значением, чтобы поле сравнивалось с ним без вычисления выражения
+	top := operands[len(operands)-1]
+	if token.Type() == scanner.TokenTypeOpNegate && top.isToken() && top.token.Type() != scanner.TokenTypeID {
+		negative, err := negateNumber(top.token)
+		if err != nil {
+			return nil, err
+		}
+		operands[len(operands)-1] = operand{token: negative}
+
+		return operands, nil
+	}
+
+	args := make([]table.Expression, 0, count)
+	for _, o := range operands[len(operands)-count:] {
+		arg, err := o.expression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	operands = operands[:len(operands)-count]
+
+	var expr table.Expression
+	switch token.Type() {
+	case scanner.TokenTypeOpNegate:
+		expr = expression.NegateExpression{Operand: args[0]}
+	case scanner.TokenTypeFunction:
+		var err error
+		if expr, err = makeFunction(token, args); err != nil {
+			return nil, err
+		}
+	default:
+		expr = expression.ArithmeticExpression{
+			Type:  table.ArithmeticOperationType(token.Value().(string)),
+			Left:  args[0],
+			Right: args[1],
+		}
+	}
+
+	return append(operands, operand{expr: expr}), nil
+}
+
+// makePredicate строит операцию in, between или is [not] null из операндов в конце стека
+func makePredicate(tokenType scanner.TokenType, operands []operand) ([]operand, error) {
+	switch tokenType {
+	case scanner.TokenTypeOpIn:
+		return makeIn(operands)
+	case scanner.TokenTypeOpBetweenAnd:
+		if len(operands) < 3 {
+			return nil, fmt.Errorf("invalid where format")
+		}
+		args := operands[len(operands)-3:]
+		for i, arg := range args {
+			if !arg.isToken() || (i == 0) != (arg.token.Type() == scanner.TokenTypeID) || i > 0 && !isValueToken(arg.token) {
+				return nil, fmt.Errorf("invalid between format")
 			}
-			arg1, arg2 := ret[len(ret)-1], ret[len(ret)-2]
-			ret = ret[:len(ret)-2]
-			ret = append(ret, operation.OrOperation{
-				Left:  arg1,
-				Right: arg2,
-			})
-		default:
-			tokenQueue = append(tokenQueue, token)
 		}
+
+		return append(operands[:len(operands)-3], operand{cond: operation.BetweenOperation{
+			ColumnName: args[0].token.Value().(string),
+			From:       args[1].token.Value(),
+			To:         args[2].token.Value(),
+		}}), nil
 	}
-	if len(ret) != 1 {
+
+	if len(operands) < 2 {
 		return nil, fmt.Errorf("invalid where format")
 	}
+	arg, null := operands[len(operands)-2], operands[len(operands)-1]
+	if !null.isToken() || null.token.Type() != scanner.TokenTypeNull {
+		return nil, fmt.Errorf("is should be followed by null")
+	}
+	argExpr, err := arg.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := operand{expr: expression.IsNullExpression{Operand: argExpr, Not: tokenType == scanner.TokenTypeOpIsNot}}
+	if arg.isToken() && arg.token.Type() == scanner.TokenTypeID {
+		ret.cond = operation.IsNullOperation{ColumnName: arg.token.Value().(string)}
+		if tokenType == scanner.TokenTypeOpIsNot {
+			ret.cond = operation.NotOperation{Operation: ret.cond}
+		}
+	} else {
+		ret.cond = operation.NewExpressionOperation(ret.expr)
+	}
+
+	return append(operands[:len(operands)-2], ret), nil
+}
+
+// makeIn собирает значения списка до отметки его начала или использует результат вложенного запроса
+func makeIn(operands []operand) ([]operand, error) {
+	if n := len(operands); n >= 2 && operands[n-1].isToken() && operands[n-1].token.Type() == scanner.TokenTypeSubquery {
+		if !operands[n-2].isToken() || operands[n-2].token.Type() != scanner.TokenTypeID {
+			return nil, fmt.Errorf("in with subquery should be applied to field")
+		}
+
+		return append(operands[:n-2], operand{cond: operation.InSubqueryOperation{
+			ColumnName: operands[n-2].token.Value().(string),
+			Result:     operands[n-1].token.Value().(Subquery).Result,
+		}}), nil
+	}
+
+	i := len(operands) - 1
+	for i >= 0 && !(operands[i].isToken() && operands[i].token.Type() == scanner.TokenTypeOpenCurlyBracket) {
+		i--
+	}
+	if i < 1 || !operands[i-1].isToken() || operands[i-1].token.Type() != scanner.TokenTypeID {
+		return nil, fmt.Errorf("in should be followed by list of values in brackets")
+	}
+
+	values := make([]interface{}, 0, len(operands)-i-1)
+	for _, val := range operands[i+1:] {
+		if !val.isToken() || !isValueToken(val.token) {
+			return nil, fmt.Errorf("invalid value in list: '%v'", val.token.Value())
+		}
+		values = append(values, val.token.Value())
+	}
+
+	return append(operands[:i-1], operand{cond: operation.InOperation{
+		ColumnName: operands[i-1].token.Value().(string),
+		Values:     values,
+	}}), nil
+}
+
+// negateNumber превращает смену знака числа в отрицательное число
+func negateNumber(token scanner.Token) (scanner.Token, error) {
+	switch n := token.Value().(type) {
+	case float64:
+		return scanner.NewToken(-n, scanner.TokenTypeNumber), nil
+	case int64:
+		return scanner.NewToken(-n, scanner.TokenTypeNumber), nil
+	}
+
+	return scanner.Token{}, fmt.Errorf("only number can be negative, got '%v'", token.Value())
+}
+
+func isValueToken(token scanner.Token) bool {
+	switch token.Type() {
+	case scanner.TokenTypeString, scanner.TokenTypeNumber, scanner.TokenTypeBool:
+		return true
+	}
+
+	return false
+}
+
+func makeCompareOperation(op table.CompareOperationType, id, val scanner.Token) (table.LogicalOperation, error) {
+	swapped := id.Type() != scanner.TokenTypeID
+	if swapped {
+		val, id = id, val
+	}
+	if id.Type() != scanner.TokenTypeID || !isValueToken(val) {
+		return nil, fmt.Errorf("invalid where format")
+	}
+
+	compareValue := val.Value()
+	switch op {
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike, table.CompareOperationTypeRegexp:
+		pattern, valid := compareValue.(string)
+		if swapped || !valid {
+			return nil, fmt.Errorf("%s should be followed by string pattern", op)
+		}
+		// Шаблон компилируется один раз, а не для каждой строки таблицы
+		compiled, err := value.CompilePattern(pattern, op)
+		if err != nil {
+			return nil, err
+		}
+		compareValue = compiled
+	}
+	// Значение указано слева от поля: '18 < age' равносильно 'age > 18'
+	if swapped {
+		op = op.Mirror()
+	}
+
+	return operation.DummyValueOperation{
+		CompareOperation: table.CompareValueOperation{
+			ColumnName: id.Value().(string),
+			Type:       op,
+			Val:        compareValue,
+		},
+	}, nil
+}
+
+func parseCompareOperation(tokenType scanner.TokenType) (table.CompareOperationType, bool) {
+	switch tokenType {
+	case scanner.TokenTypeOpMore:
+		return table.CompareOperationTypeMore, true
+	case scanner.TokenTypeOpLess:
+		return table.CompareOperationTypeLess, true
+	case scanner.TokenTypeOpEqual:
+		return table.CompareOperationTypeEqual, true
+	case scanner.TokenTypeOpNotEqual:
+		return table.CompareOperationTypeNotEqual, true
+	case scanner.TokenTypeOpLessOrEqual:
+		return table.CompareOperationTypeLessOrEqual, true
+	case scanner.TokenTypeOpMoreOrEqual:
+		return table.CompareOperationTypeMoreOrEqual, true
+	case scanner.TokenTypeOpLike:
+		return table.CompareOperationTypeLike, true
+	case scanner.TokenTypeOpILike:
+		return table.CompareOperationTypeILike, true
+	case scanner.TokenTypeOpRegexp:
+		return table.CompareOperationTypeRegexp, true
+	}
 
-	return ret[0], nil
+	return "", false
 }
@@ -1,12 +1,15 @@
 package parser
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/stepan2volkov/csvdb/internal/app/scanner"
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
 	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -78,6 +81,168 @@ func TestMakeWhere(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "value before field",
+			stmt: "18 <= age;",
+			want: operation.DummyValueOperation{
+				CompareOperation: table.CompareValueOperation{
+					ColumnName: "age",
+					Type:       table.CompareOperationTypeMoreOrEqual,
+					Val:        18.0,
+				},
+			},
+		},
+		{
+			name: "not equal",
+			stmt: "fullname <> 'Mike Smith';",
+			want: operation.DummyValueOperation{
+				CompareOperation: table.CompareValueOperation{
+					ColumnName: "fullname",
+					Type:       table.CompareOperationTypeNotEqual,
+					Val:        "Mike Smith",
+				},
+			},
+		},
+		{
+			name: "not with curly brackets",
+			stmt: "not (country = 'France' or country = 'Spain');",
+			want: operation.NotOperation{
+				Operation: operation.OrOperation{
+					Left: operation.DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							ColumnName: "country",
+							Type:       table.CompareOperationTypeEqual,
+							Val:        "Spain",
+						},
+					},
+					Right: operation.DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							ColumnName: "country",
+							Type:       table.CompareOperationTypeEqual,
+							Val:        "France",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "like",
+			stmt: "country like 'S%';",
+			want: operation.DummyValueOperation{
+				CompareOperation: table.CompareValueOperation{
+					ColumnName: "country",
+					Type:       table.CompareOperationTypeLike,
+					Val:        regexp.MustCompile(`^(?s)S.*$`),
+				},
+			},
+		},
+		{
+			name: "regexp shorthand",
+			stmt: "country ~ '^[A-C]';",
+			want: operation.DummyValueOperation{
+				CompareOperation: table.CompareValueOperation{
+					ColumnName: "country",
+					Type:       table.CompareOperationTypeRegexp,
+					Val:        regexp.MustCompile(`^[A-C]`),
+				},
+			},
+		},
+		{
+			name: "in list",
+			stmt: "country in ('France', 'Spain');",
+			want: operation.InOperation{
+				ColumnName: "country",
+				Values:     []interface{}{"France", "Spain"},
+			},
+		},
+		{
+			name: "not between",
+			stmt: "age not between 18 and 30;",
+			want: operation.NotOperation{
+				Operation: operation.BetweenOperation{
+					ColumnName: "age",
+					From:       18.0,
+					To:         30.0,
+				},
+			},
+		},
+		{
+			name: "between and other condition",
+			stmt: "age between 18 and 30 and country is not null;",
+			want: operation.AndOperation{
+				Left: operation.NotOperation{
+					Operation: operation.IsNullOperation{ColumnName: "country"},
+				},
+				Right: operation.BetweenOperation{
+					ColumnName: "age",
+					From:       18.0,
+					To:         30.0,
+				},
+			},
+		},
+		{
+			name: "bool literal",
+			stmt: "paid = true;",
+			want: operation.DummyValueOperation{
+				CompareOperation: table.CompareValueOperation{
+					ColumnName: "paid",
+					Type:       table.CompareOperationTypeEqual,
+					Val:        true,
+				},
+			},
+		},
+		{
+			name: "two columns",
+			stmt: "total_revenue > total_cost;",
+			want: operation.NewExpressionOperation(expression.CompareExpression{
+				Type:  table.CompareOperationTypeMore,
+				Left:  expression.ColumnExpression{ColumnName: "total_revenue"},
+				Right: expression.ColumnExpression{ColumnName: "total_cost"},
+			}),
+		},
+		{
+			name: "expression and column with value",
+			stmt: "total_revenue - total_cost > 100 and region = 'Asia';",
+			want: operation.AndOperation{
+				Left: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						ColumnName: "region",
+						Type:       table.CompareOperationTypeEqual,
+						Val:        "Asia",
+					},
+				},
+				Right: operation.NewExpressionOperation(expression.CompareExpression{
+					Type: table.CompareOperationTypeMore,
+					Left: expression.ArithmeticExpression{
+						Type:  table.ArithmeticOperationTypeMinus,
+						Left:  expression.ColumnExpression{ColumnName: "total_revenue"},
+						Right: expression.ColumnExpression{ColumnName: "total_cost"},
+					},
+					Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(100)},
+				}),
+			},
+		},
+		{
+			name: "function is null",
+			stmt: "trim(city) is null;",
+			want: operation.NewExpressionOperation(expression.IsNullExpression{
+				Operand: expression.FunctionExpression{
+					Name: "trim",
+					Args: []table.Expression{expression.ColumnExpression{ColumnName: "city"}},
+				},
+			}),
+		},
+		{
+			name: "integer beyond float precision",
+			stmt: "id = 9007199254740993;",
+			want: operation.DummyValueOperation{
+				CompareOperation: table.CompareValueOperation{
+					ColumnName: "id",
+					Type:       table.CompareOperationTypeEqual,
+					Val:        int64(9007199254740993),
+				},
+			},
+		},
 	}
 
 	logger, _ := zap.NewDevelopment()
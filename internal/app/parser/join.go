@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+// Qualifier возвращает псевдоним присоединяемой таблицы или её наименование, если псевдоним не указан
+func (j Join) Qualifier() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+
+	return j.Tablename
+}
+
+// Qualifier возвращает псевдоним таблицы секции from или её наименование, если псевдоним не указан
+func (s SelectStmt) Qualifier() string {
+	if s.TableAlias != "" {
+		return s.TableAlias
+	}
+
+	return s.Tablename
+}
+
+func (b *selectStmtBuilder) appendJoinKeyword(value string) error {
+	switch value {
+	case KeywordInner, KeywordLeft:
+		if !b.isAfter(KeywordFrom, KeywordOn) {
+			return fmt.Errorf("%s join should be after from or on", value)
+		}
+		b.joinType = table.JoinType(value)
+
+		// Тип соединения не закрывает секцию, это делает join
+		return nil
+	case KeywordOuter:
+		if b.joinType != table.JoinTypeLeft {
+			return fmt.Errorf("outer should be after left")
+		}
+
+		return nil
+	case KeywordJoin:
+		if !b.isAfter(KeywordFrom, KeywordOn) {
+			return fmt.Errorf("join should be after from or on")
+		}
+		if err := b.checkSectionCompleted(); err != nil {
+			return err
+		}
+		joinType := b.joinType
+		if joinType == "" {
+			joinType = table.JoinTypeInner
+		}
+		b.joins = append(b.joins, Join{Type: joinType})
+		b.joinType = ""
+	case KeywordOn:
+		if b.lastKeyword != KeywordJoin {
+			return fmt.Errorf("on should be after join")
+		}
+		if err := b.checkTableCompleted(); err != nil {
+			return err
+		}
+	}
+	b.lastKeyword = value
+
+	return nil
+}
+
+// currentTable возвращает наименование, псевдоним и вложенный запрос таблицы секции from или последней секции join
+func (b *selectStmtBuilder) currentTable() (tablename, alias *string, source **SelectStmt) {
+	if b.lastKeyword == KeywordJoin {
+		j := &b.joins[len(b.joins)-1]
+
+		return &j.Tablename, &j.Alias, &j.Source
+	}
+
+	return &b.tablename, &b.tableAlias, &b.source
+}
+
+// checkTableCompleted проверяет, что в секции from или join указана таблица, а для вложенного запроса - псевдоним
+func (b *selectStmtBuilder) checkTableCompleted() error {
+	tablename, alias, source := b.currentTable()
+	switch {
+	case *tablename == "" && *source == nil:
+		return fmt.Errorf("tablename should be specified after %s", b.lastKeyword)
+	case b.expectAlias:
+		return fmt.Errorf("alias should be specified after as")
+	case *source != nil && *alias == "":
+		return fmt.Errorf("subquery in %s should have alias", b.lastKeyword)
+	}
+
+	return nil
+}
+
+// appendTable запоминает наименование таблицы, а идентификатор после него - как её псевдоним
+func (b *selectStmtBuilder) appendTable(name string) error {
+	tablename, alias, source := b.currentTable()
+	switch {
+	case *tablename == "" && *source == nil:
+		*tablename = name
+	case *alias == "":
+		*alias = name
+		b.expectAlias = false
+	default:
+		return fmt.Errorf("tablename should be specified once")
+	}
+
+	return nil
+}
+
+func (b *selectStmtBuilder) appendTableAs() error {
+	tablename, alias, source := b.currentTable()
+	if *tablename == "" && *source == nil || *alias != "" || b.expectAlias {
+		return fmt.Errorf("as should be after tablename")
+	}
+	b.expectAlias = true
+
+	return nil
+}
+
+// checkAliases проверяет, что к полям каждой таблицы соединения можно обратиться по своему псевдониму
+func (b *selectStmtBuilder) checkAliases() error {
+	if len(b.joins) == 0 {
+		return nil
+	}
+
+	qualifiers := map[string]struct{}{
+		SelectStmt{Tablename: b.tablename, TableAlias: b.tableAlias}.Qualifier(): {},
+	}
+	for _, j := range b.joins {
+		if _, found := qualifiers[j.Qualifier()]; found {
+			return fmt.Errorf("table alias '%s' is specified twice", j.Qualifier())
+		}
+		qualifiers[j.Qualifier()] = struct{}{}
+	}
+
+	return nil
+}
+
+// makeJoinKeys собирает пары полей из условия on, которое состоит из равенств полей, объединённых and
+func makeJoinKeys(tokens []scanner.Token) ([]table.JoinKey, error) {
+	var keys []table.JoinKey
+	var fields []string
+	ands := 0
+
+	for _, token := range tokens {
+		switch token.Type() {
+		case scanner.TokenTypeID:
+			fields = append(fields, token.Value().(string))
+		case scanner.TokenTypeOpEqual:
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("join condition should compare two fields")
+			}
+			keys = append(keys, table.JoinKey{Left: fields[0], Right: fields[1]})
+			fields = nil
+		case scanner.TokenTypeOpAnd:
+			ands++
+		default:
+			return nil, fmt.Errorf("join condition should be equality of fields combined with and")
+		}
+	}
+	if len(keys) == 0 || len(fields) != 0 || ands != len(keys)-1 {
+		return nil, fmt.Errorf("join condition should compare two fields")
+	}
+
+	return keys, nil
+}
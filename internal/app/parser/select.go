@@ -2,9 +2,11 @@ package parser
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/stepan2volkov/csvdb/internal/app/scanner"
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
 	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
 )
 
@@ -12,21 +14,166 @@ const (
 	KeywordSelect = "select"
 	KeywordFrom   = "from"
 	KeywordWhere  = "where"
+	KeywordGroup  = "group"
+	KeywordHaving = "having"
+	KeywordOrder  = "order"
+	KeywordBy     = "by"
+	KeywordAsc    = "asc"
+	KeywordDesc   = "desc"
+	KeywordLimit  = "limit"
+	KeywordOffset = "offset"
+	KeywordAs     = "as"
+	KeywordJoin   = "join"
+	KeywordInner  = "inner"
+	KeywordLeft   = "left"
+	KeywordOuter  = "outer"
+	KeywordOn     = "on"
 )
 
+// Ключевые слова операций над результатами запросов
+const (
+	KeywordUnion     = "union"
+	KeywordAll       = "all"
+	KeywordIntersect = "intersect"
+	KeywordExcept    = "except"
+	KeywordDistinct  = "distinct"
+	KeywordOver      = "over"
+	KeywordPartition = "partition"
+	KeywordWith      = "with"
+	KeywordCreate    = "create"
+	KeywordDrop      = "drop"
+	KeywordInsert    = "insert"
+	KeywordInto      = "into"
+	KeywordValues    = "values"
+	KeywordUpdate    = "update"
+	KeywordSet       = "set"
+	KeywordDelete    = "delete"
+)
+
+// Секции, которые состоят из двух ключевых слов
+const (
+	sectionGroupBy = KeywordGroup + " " + KeywordBy
+	sectionOrderBy = KeywordOrder + " " + KeywordBy
+)
+
+const allFieldsSign = "*"
+
 type SelectStmt struct {
-	Fields    []string
-	AllField  bool
+	// Fields - наименования полей результата в порядке, указанном в запросе.
+	// Поле может повторяться, '*' и 't.*' раскрываются в поля таблицы.
+	// При Distinct из результата исключаются повторяющиеся строки.
+	Fields     []string
+	AllField   bool
+	Distinct   bool
+	Computed   []ComputedField
+	Aggregates []table.Aggregate
+	Windows    []table.Window
+	// HiddenAggregates вычисляются для having и order by, но не попадают в результат
+	HiddenAggregates []table.Aggregate
+	Tablename        string
+	TableAlias       string
+	Source           *SelectStmt
+	Joins            []Join
+	Subqueries       []Subquery
+	Filter           table.LogicalOperation
+	GroupBy          []string
+	Having           table.LogicalOperation
+	OrderBy          []table.OrderField
+	Limit            *Limit
+	// SetOperations объединяются с результатом запроса слева направо, после чего
+	// к общему результату применяются OrderBy и Limit
+	SetOperations []SetOperation
+	// With - временные таблицы, которые доступны только в этом запросе и вложенных в него запросах
+	With []CommonTable
+}
+
+func (s SelectStmt) Grouped() bool {
+	return len(s.Aggregates) > 0 || len(s.HiddenAggregates) > 0 || len(s.GroupBy) > 0
+}
+
+// Join - таблица, строки которой присоединяются к строкам таблицы секции from по равенству полей.
+// К полям таблиц соединения можно обратиться по псевдониму: 's.country'.
+// Source - вложенный запрос, который указан вместо таблицы, как и SelectStmt.Source.
+type Join struct {
+	Type      table.JoinType
 	Tablename string
-	Filter    table.LogicalOperation
+	Alias     string
+	Source    *SelectStmt
+	On        []table.JoinKey
+}
+
+// Subquery - вложенный запрос условия in. Его результат должен быть получен до применения условий запроса.
+type Subquery struct {
+	Stmt   SelectStmt
+	Result *operation.SubqueryResult
+}
+
+type Limit struct {
+	Count  int
+	Offset int
 }
 
 func MakeSelectStmt(tokens []scanner.Token) (SelectStmt, error) {
+	with, tokens, err := splitWith(tokens)
+	if err != nil {
+		return SelectStmt{}, err
+	}
+	parts, opTypes, err := splitSetOperations(tokens)
+	if err != nil {
+		return SelectStmt{}, err
+	}
+	stmt, err := makeSimpleSelectStmt(parts[0])
+	if err != nil {
+		return SelectStmt{}, err
+	}
+	for i, opType := range opTypes {
+		sub, err := makeSimpleSelectStmt(parts[i+1])
+		if err != nil {
+			return SelectStmt{}, err
+		}
+		stmt.SetOperations = append(stmt.SetOperations, SetOperation{Type: opType, Stmt: sub})
+	}
+	stmt.With = with
+
+	return moveSetOrder(stmt)
+}
+
+// makeSimpleSelectStmt разбирает запрос без операций над результатами запросов
+func makeSimpleSelectStmt(tokens []scanner.Token) (SelectStmt, error) {
 	var builder selectStmtBuilder
-	for _, token := range tokens {
-		if err := builder.append(token); err != nil {
+	for i := 0; i < len(tokens); i++ {
+		if isWindowCall(tokens, i) {
+			end := subqueryEnd(tokens, i+2)
+			if end < 0 {
+				return SelectStmt{}, fmt.Errorf("window should be closed by bracket")
+			}
+			if err := builder.appendWindow(tokens[i], tokens[i+3:end]); err != nil {
+				return SelectStmt{}, err
+			}
+			i = end
+
+			continue
+		}
+		if !isSubqueryStart(tokens, i) {
+			if err := builder.append(tokens[i]); err != nil {
+				return SelectStmt{}, err
+			}
+
+			continue
+		}
+
+		end := subqueryEnd(tokens, i)
+		if end < 0 {
+			return SelectStmt{}, fmt.Errorf("subquery should be closed by bracket")
+		}
+		sub, err := MakeSelectStmt(tokens[i+1 : end])
+		if err != nil {
+			return SelectStmt{}, err
+		}
+		if err := builder.appendSubquery(sub); err != nil {
 			return SelectStmt{}, err
 		}
+		i = end
 	}
 
 	return builder.build()
@@ -34,13 +181,56 @@ func MakeSelectStmt(tokens []scanner.Token) (SelectStmt, error) {
 
 type selectStmtBuilder struct {
 	lastKeyword string
-	allFields   bool
-	fields      []string
-	tablename   string
-	conditions  []scanner.Token
+	items       []selectItem
+	// expectAlias - после выражения указано as, следующий идентификатор - наименование поля
+	expectAlias      bool
+	distinct         bool
+	fields           []string
+	computed         []ComputedField
+	aggregates       []table.Aggregate
+	windows          []table.Window
+	nestedAggregates []table.Aggregate
+	hiddenAggregates []table.Aggregate
+	tablename        string
+	tableAlias       string
+	source           *SelectStmt
+	joins            []Join
+	subqueries       []Subquery
+	joinType         table.JoinType
+	joinConditions   []scanner.Token
+	conditions       []scanner.Token
+	groupBy          []string
+	having           []scanner.Token
+	orderBy          []table.OrderField
+	limit            *Limit
 }
 
 func (b *selectStmtBuilder) build() (SelectStmt, error) {
+	if err := b.checkSectionCompleted(); err != nil {
+		return SelectStmt{}, err
+	}
+	b.fields, b.computed = b.makeSelectFields()
+	for _, f := range b.fields {
+		if isAllFields(f) && (len(b.aggregates) > 0 || len(b.hiddenAggregates) > 0 || len(b.groupBy) > 0) {
+			return SelectStmt{}, fmt.Errorf("'%s' cannot be used with group by or aggregate functions", f)
+		}
+	}
+	allFields := len(b.fields) == 1 && b.fields[0] == allFieldsSign
+	if allFields || len(b.fields) == 0 {
+		b.fields = nil
+	}
+	if len(b.windows) > 0 && (len(b.aggregates) > 0 || len(b.hiddenAggregates) > 0 || len(b.groupBy) > 0) {
+		return SelectStmt{}, fmt.Errorf("window functions cannot be used with group by or aggregate functions")
+	}
+	if err := b.checkGroupedFields(); err != nil {
+		return SelectStmt{}, err
+	}
+	if b.limit != nil && (b.limit.Count < 0 || b.limit.Offset < 0) {
+		return SelectStmt{}, fmt.Errorf("invalid format of limit section")
+	}
+	if err := b.checkAliases(); err != nil {
+		return SelectStmt{}, err
+	}
 	var filter table.LogicalOperation = operation.DummyValueOperation{
 		CompareOperation: table.CompareValueOperation{
 			Type: table.CompareOperationTypeDummy,
@@ -53,72 +243,397 @@ func (b *selectStmtBuilder) build() (SelectStmt, error) {
 		}
 		filter = newFilter
 	}
+	var having table.LogicalOperation
+	if len(b.having) > 0 {
+		newHaving, err := makeWhere(b.having)
+		if err != nil {
+			return SelectStmt{}, err
+		}
+		having = newHaving
+	}
 
-	return SelectStmt{
-		Fields:    b.fields,
-		AllField:  b.allFields,
-		Tablename: b.tablename,
-		Filter:    filter,
-	}, nil
+	stmt := SelectStmt{
+		Fields:           b.fields,
+		AllField:         allFields,
+		Distinct:         b.distinct,
+		Computed:         b.computed,
+		Aggregates:       b.aggregates,
+		Windows:          b.windows,
+		HiddenAggregates: b.hiddenAggregates,
+		Tablename:        b.tablename,
+		TableAlias:       b.tableAlias,
+		Source:           b.source,
+		Joins:            b.joins,
+		Subqueries:       b.subqueries,
+		Filter:           filter,
+		GroupBy:          b.groupBy,
+		Having:           having,
+		OrderBy:          b.orderBy,
+		Limit:            b.limit,
+	}
+	// Без сортировки, группировки, оконных функций и исключения повторов достаточно найти первые offset+count строк
+	if stmt.Limit != nil && len(stmt.OrderBy) == 0 && !stmt.Grouped() && len(stmt.Windows) == 0 && !stmt.Distinct {
+		limit := b.limit.Offset + b.limit.Count
+		if b.limit.Count > math.MaxInt-b.limit.Offset {
+			limit = math.MaxInt
+		}
+		stmt.Filter = operation.LimitOperation{
+			Operation: filter,
+			Limit:     limit,
+		}
+	}
+
+	return stmt, nil
 }
 
-func (b *selectStmtBuilder) append(token scanner.Token) error {
-	if token.Type() == scanner.TokenTypeKeyword {
-		value := token.Value().(string)
-		switch value {
-		case KeywordSelect:
-			if b.lastKeyword != "" {
-				return fmt.Errorf("select should be the first word")
-			}
-		case KeywordFrom:
-			if b.lastKeyword != KeywordSelect {
-				return fmt.Errorf("from section should be after select")
-			}
-			if b.fields == nil && !b.allFields {
-				return fmt.Errorf("fields should be specified after select")
-			}
-		case KeywordWhere:
-			if b.lastKeyword != KeywordFrom {
-				return fmt.Errorf("where section should be after from")
-			}
-			if b.tablename == "" {
-				return fmt.Errorf("tablename should be specified after from")
-			}
+// checkGroupedFields проверяет, что при группировке выбираются только поля группировки
+func (b *selectStmtBuilder) checkGroupedFields() error {
+	if len(b.aggregates) == 0 && len(b.hiddenAggregates) == 0 && len(b.groupBy) == 0 {
+		if len(b.having) > 0 {
+			return fmt.Errorf("having section requires group by or aggregate functions")
 		}
-		b.lastKeyword = value
 
 		return nil
 	}
+
+	groupBy := make(map[string]struct{}, len(b.groupBy))
+	for _, f := range b.groupBy {
+		groupBy[f] = struct{}{}
+	}
+	for _, item := range b.items {
+		column, isColumn := item.expr.(expression.ColumnExpression)
+		if !isColumn || item.aggregate != nil || item.alias != "" {
+			continue
+		}
+		if _, found := groupBy[column.ColumnName]; !found {
+			return fmt.Errorf("field '%s' should be used in aggregate function or group by section", column.ColumnName)
+		}
+	}
+
+	return nil
+}
+
+func (b *selectStmtBuilder) append(token scanner.Token) error {
+	if token.Type() == scanner.TokenTypeKeyword {
+		return b.appendKeyword(token.Value().(string))
+	}
+	if b.lastKeyword == KeywordSelect {
+		return b.appendSelect(token)
+	}
 	if token.Type() == scanner.TokenTypeID {
 		switch b.lastKeyword {
-		case KeywordSelect:
-			if b.allFields {
-				return fmt.Errorf("invalid format of select stmt")
-			}
-			value := token.Value().(string)
-			if value == "*" {
-				b.allFields = true
-
-				return nil
-			}
-			b.fields = append(b.fields, value)
-		case KeywordFrom:
-			if b.tablename != "" {
-				return fmt.Errorf("tablename should be specified once")
-			}
-			b.tablename = token.Value().(string)
+		case KeywordFrom, KeywordJoin:
+			return b.appendTable(token.Value().(string))
+		case KeywordOn:
+			b.joinConditions = append(b.joinConditions, token)
 		case KeywordWhere:
 			b.conditions = append(b.conditions, token)
+		case sectionGroupBy:
+			b.groupBy = append(b.groupBy, token.Value().(string))
+		case KeywordHaving:
+			b.having = append(b.having, token)
+		case sectionOrderBy:
+			b.orderBy = append(b.orderBy, table.OrderField{Name: token.Value().(string)})
 		default:
 			return fmt.Errorf("select should be the first word")
 		}
 
 		return nil
 	}
-	if b.lastKeyword != KeywordWhere {
+	if token.Type() == scanner.TokenTypeFunction {
+		return b.appendFunction(token)
+	}
+	switch b.lastKeyword {
+	case KeywordLimit, KeywordOffset:
+		return b.appendLimit(token)
+	case KeywordOn:
+		b.joinConditions = append(b.joinConditions, token)
+	case KeywordWhere:
+		b.conditions = append(b.conditions, token)
+	case KeywordHaving:
+		b.having = append(b.having, token)
+	default:
 		return fmt.Errorf("invalid format of select stmt")
 	}
-	b.conditions = append(b.conditions, token)
+
+	return nil
+}
+
+func (b *selectStmtBuilder) appendKeyword(value string) error {
+	if b.joinType != "" && value != KeywordOuter && value != KeywordJoin {
+		return fmt.Errorf("join should be after %s", b.joinType)
+	}
+	if err := checkSelectKeyword(value); err != nil {
+		return err
+	}
+
+	switch value {
+	case KeywordSelect:
+		if b.lastKeyword != "" {
+			return fmt.Errorf("select should be the first word")
+		}
+	case KeywordFrom:
+		if b.lastKeyword != KeywordSelect {
+			return fmt.Errorf("from section should be after select")
+		}
+		if len(b.items) == 0 {
+			return fmt.Errorf("fields should be specified after select")
+		}
+	case KeywordInner, KeywordLeft, KeywordOuter, KeywordJoin, KeywordOn:
+		return b.appendJoinKeyword(value)
+	case KeywordDistinct:
+		if b.lastKeyword != KeywordSelect || len(b.items) > 0 || b.distinct {
+			return fmt.Errorf("distinct should be right after select")
+		}
+		b.distinct = true
+
+		// distinct относится к секции select и не открывает новую секцию
+		return nil
+	case KeywordWhere:
+		if !b.isAfter(KeywordFrom, KeywordOn) {
+			return fmt.Errorf("where section should be after from")
+		}
+	case KeywordGroup:
+		if !b.isAfter(KeywordFrom, KeywordOn, KeywordWhere) {
+			return fmt.Errorf("group by section should be after from or where")
+		}
+	case KeywordHaving:
+		if !b.isAfter(KeywordFrom, KeywordOn, KeywordWhere, sectionGroupBy) {
+			return fmt.Errorf("having section should be after from, where or group by")
+		}
+	case KeywordOrder:
+		if !b.isAfter(KeywordFrom, KeywordOn, KeywordWhere, sectionGroupBy, KeywordHaving) {
+			return fmt.Errorf("order by section should be after from, where, group by or having")
+		}
+	case KeywordAs:
+		if b.isAfter(KeywordFrom, KeywordJoin) {
+			return b.appendTableAs()
+		}
+		if b.lastKeyword != KeywordSelect || len(b.items) == 0 || b.expectAlias {
+			return fmt.Errorf("as should be after expression in select section")
+		}
+		b.expectAlias = true
+
+		// Наименование поля не открывает новую секцию
+		return nil
+	case KeywordBy:
+		if b.lastKeyword != KeywordGroup && b.lastKeyword != KeywordOrder {
+			return fmt.Errorf("by should be after group or order")
+		}
+		b.lastKeyword = b.lastKeyword + " " + KeywordBy
+
+		return nil
+	case KeywordAsc, KeywordDesc:
+		if b.lastKeyword != sectionOrderBy || len(b.orderBy) == 0 {
+			return fmt.Errorf("%s should be after field in order by section", value)
+		}
+		b.orderBy[len(b.orderBy)-1].Desc = value == KeywordDesc
+
+		// Направление сортировки не открывает новую секцию
+		return nil
+	case KeywordLimit:
+		if !b.isAfter(KeywordFrom, KeywordOn, KeywordWhere, sectionGroupBy, KeywordHaving, sectionOrderBy) {
+			return fmt.Errorf("limit section should be after from, where, group by, having or order by")
+		}
+		b.limit = &Limit{Count: -1}
+	case KeywordOffset:
+		if b.lastKeyword != KeywordLimit {
+			return fmt.Errorf("offset should be after limit")
+		}
+		if b.limit.Count < 0 {
+			return fmt.Errorf("count should be specified after limit")
+		}
+		b.limit.Offset = -1
+	}
+	if err := b.checkSectionCompleted(); err != nil {
+		return err
+	}
+	b.lastKeyword = value
+
+	return nil
+}
+
+// checkSelectKeyword проверяет, что ключевое слово может быть в секциях запроса select
+func checkSelectKeyword(value string) error {
+	switch value {
+	case KeywordUnion, KeywordIntersect, KeywordExcept:
+		return fmt.Errorf("%s should be between two selects", value)
+	case KeywordAll:
+		return fmt.Errorf("all should be after union")
+	case KeywordWith, KeywordCreate, KeywordDrop, KeywordInsert, KeywordUpdate, KeywordDelete:
+		return fmt.Errorf("%s should be at the beginning of query", value)
+	case KeywordInto, KeywordValues:
+		return fmt.Errorf("%s is allowed only in insert stmt", value)
+	case KeywordSet:
+		return fmt.Errorf("set is allowed only in update stmt")
+	case KeywordOver, KeywordPartition:
+		return fmt.Errorf("%s is allowed only in window of function in select section", value)
+	}
+
+	return nil
+}
+
+func (b *selectStmtBuilder) isAfter(keywords ...string) bool {
+	for _, keyword := range keywords {
+		if b.lastKeyword == keyword {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSectionCompleted проверяет, что в закрываемой секции указано всё необходимое
+func (b *selectStmtBuilder) checkSectionCompleted() error {
+	switch b.lastKeyword {
+	case KeywordSelect:
+		if b.expectAlias {
+			return fmt.Errorf("alias should be specified after as")
+		}
+	case KeywordFrom:
+		return b.checkTableCompleted()
+	case KeywordJoin:
+		if err := b.checkTableCompleted(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("join should be followed by on")
+	case KeywordOn:
+		keys, err := makeJoinKeys(b.joinConditions)
+		if err != nil {
+			return err
+		}
+		b.joins[len(b.joins)-1].On = keys
+		b.joinConditions = nil
+	case KeywordWhere:
+		if len(b.conditions) == 0 {
+			return fmt.Errorf("condition should be specified after where")
+		}
+	case KeywordHaving:
+		if len(b.having) == 0 {
+			return fmt.Errorf("condition should be specified after having")
+		}
+	case sectionGroupBy:
+		if len(b.groupBy) == 0 {
+			return fmt.Errorf("fields should be specified after group by")
+		}
+	case sectionOrderBy:
+		if len(b.orderBy) == 0 {
+			return fmt.Errorf("fields should be specified after order by")
+		}
+	}
+
+	return nil
+}
+
+func (b *selectStmtBuilder) appendFunction(token scanner.Token) error {
+	_, aggregateErr := parseAggregateType(token.Value().(string))
+	switch {
+	case b.lastKeyword == KeywordWhere:
+		b.conditions = append(b.conditions, token)
+	case b.lastKeyword == KeywordHaving && aggregateErr != nil:
+		b.having = append(b.having, token)
+	case b.lastKeyword == KeywordHaving:
+		if len(b.having) == 0 || b.having[len(b.having)-1].Type() != scanner.TokenTypeID {
+			return fmt.Errorf("function %s requires an argument", token.Value())
+		}
+		a, err := makeAggregate(token, b.having[len(b.having)-1].Value().(string))
+		if err != nil {
+			return err
+		}
+		b.addHiddenAggregate(a)
+		b.having[len(b.having)-1] = scanner.NewToken(a.Name(), scanner.TokenTypeID)
+	case b.lastKeyword == sectionOrderBy:
+		if len(b.orderBy) == 0 {
+			return fmt.Errorf("function %s requires an argument", token.Value())
+		}
+		a, err := makeAggregate(token, b.orderBy[len(b.orderBy)-1].Name)
+		if err != nil {
+			return err
+		}
+		b.addHiddenAggregate(a)
+		b.orderBy[len(b.orderBy)-1].Name = a.Name()
+	default:
+		return fmt.Errorf("function %s is not allowed here", token.Value())
+	}
+
+	return nil
+}
+
+// addHiddenAggregate запоминает функцию, если она не вычисляется для секции select
+func (b *selectStmtBuilder) addHiddenAggregate(a table.Aggregate) {
+	for _, existing := range b.aggregates {
+		if existing == a {
+			return
+		}
+	}
+	for _, existing := range b.hiddenAggregates {
+		if existing == a {
+			return
+		}
+	}
+	b.hiddenAggregates = append(b.hiddenAggregates, a)
+}
+
+// makeAggregate использует последнее указанное поле в качестве аргумента функции
+func makeAggregate(token scanner.Token, arg string) (table.Aggregate, error) {
+	aggregateType, err := parseAggregateType(token.Value().(string))
+	if err != nil {
+		return table.Aggregate{}, err
+	}
+	if arg == allFieldsSign && aggregateType != table.AggregateTypeCount {
+		return table.Aggregate{}, fmt.Errorf("function %s cannot be applied to '*'", aggregateType)
+	}
+	if token.Distinct() && aggregateType != table.AggregateTypeCount {
+		return table.Aggregate{}, fmt.Errorf("distinct is supported only in function %s", table.AggregateTypeCount)
+	}
+	if token.Distinct() && arg == allFieldsSign {
+		return table.Aggregate{}, fmt.Errorf("distinct cannot be applied to '*'")
+	}
+
+	return table.Aggregate{
+		Type:       aggregateType,
+		ColumnName: arg,
+		Distinct:   token.Distinct(),
+	}, nil
+}
+
+func parseAggregateType(name string) (table.AggregateType, error) {
+	switch aggregateType := table.AggregateType(name); aggregateType {
+	case table.AggregateTypeCount, table.AggregateTypeSum, table.AggregateTypeAvg,
+		table.AggregateTypeMin, table.AggregateTypeMax:
+		return aggregateType, nil
+	}
+
+	return "", fmt.Errorf("unknown function: %s", name)
+}
+
+// maxLimit - наибольшее значение limit и offset
+const maxLimit = math.MaxInt32
+
+func (b *selectStmtBuilder) appendLimit(token scanner.Token) error {
+	if token.Type() != scanner.TokenTypeNumber {
+		return fmt.Errorf("%s should be a number", b.lastKeyword)
+	}
+	value, valid := token.Value().(float64)
+	if !valid {
+		return fmt.Errorf("%s is too large", b.lastKeyword)
+	}
+	if value != math.Trunc(value) {
+		return fmt.Errorf("%s should be an integer", b.lastKeyword)
+	}
+	if value > maxLimit {
+		return fmt.Errorf("%s is too large", b.lastKeyword)
+	}
+
+	switch {
+	case b.lastKeyword == KeywordLimit && b.limit.Count < 0:
+		b.limit.Count = int(value)
+	case b.lastKeyword == KeywordOffset && b.limit.Offset < 0:
+		b.limit.Offset = int(value)
+	default:
+		return fmt.Errorf("%s should be specified once", b.lastKeyword)
+	}
 
 	return nil
 }
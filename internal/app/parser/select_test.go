@@ -6,7 +6,9 @@ import (
 
 	"github.com/stepan2volkov/csvdb/internal/app/scanner"
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
 	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -47,6 +49,426 @@ func TestMakeSelectStmt(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with order by",
+			stmt: "select col_1, col_2 from table where col_1 > 2 order by col_2 desc, col_1;",
+			want: SelectStmt{
+				Fields:    []string{"col_1", "col_2"},
+				Tablename: "table",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						ColumnName: "col_1",
+						Type:       table.CompareOperationTypeMore,
+						Val:        2.0,
+					},
+				},
+				OrderBy: []table.OrderField{
+					{Name: "col_2", Desc: true},
+					{Name: "col_1", Desc: false},
+				},
+			},
+		},
+		{
+			name: "with limit and offset",
+			stmt: "SELECT * FROM table WHERE col_1 > 2 LIMIT 10 OFFSET 5;",
+			want: SelectStmt{
+				AllField:  true,
+				Tablename: "table",
+				Filter: operation.LimitOperation{
+					Operation: operation.DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							ColumnName: "col_1",
+							Type:       table.CompareOperationTypeMore,
+							Val:        2.0,
+						},
+					},
+					Limit: 15,
+				},
+				Limit: &Limit{Count: 10, Offset: 5},
+			},
+		},
+		{
+			name: "with order by and limit",
+			stmt: "SELECT * FROM table ORDER BY col_1 LIMIT 10;",
+			want: SelectStmt{
+				AllField:  true,
+				Tablename: "table",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				OrderBy: []table.OrderField{{Name: "col_1"}},
+				Limit:   &Limit{Count: 10},
+			},
+		},
+		{
+			name: "with aggregates",
+			stmt: "SELECT COUNT(*), SUM(total_profit), MAX(country) FROM sales LIMIT 1;",
+			want: SelectStmt{
+				Fields: []string{"count(*)", "sum(total_profit)", "max(country)"},
+				Aggregates: []table.Aggregate{
+					{Type: table.AggregateTypeCount, ColumnName: "*"},
+					{Type: table.AggregateTypeSum, ColumnName: "total_profit"},
+					{Type: table.AggregateTypeMax, ColumnName: "country"},
+				},
+				Tablename: "sales",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				Limit: &Limit{Count: 1},
+			},
+		},
+		{
+			name: "with group by and having",
+			stmt: "SELECT region, SUM(total_profit) FROM sales GROUP BY region HAVING COUNT(*) > 10 ORDER BY SUM(total_profit) DESC;",
+			want: SelectStmt{
+				Fields: []string{"region", "sum(total_profit)"},
+				Aggregates: []table.Aggregate{
+					{Type: table.AggregateTypeSum, ColumnName: "total_profit"},
+				},
+				HiddenAggregates: []table.Aggregate{
+					{Type: table.AggregateTypeCount, ColumnName: "*"},
+				},
+				Tablename: "sales",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				GroupBy: []string{"region"},
+				Having: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						ColumnName: "count(*)",
+						Type:       table.CompareOperationTypeMore,
+						Val:        10.0,
+					},
+				},
+				OrderBy: []table.OrderField{{Name: "sum(total_profit)", Desc: true}},
+			},
+		},
+		{
+			name: "with computed fields",
+			stmt: "select country, total_revenue - total_cost * 2 as margin, upper(country) from sales;",
+			want: SelectStmt{
+				Fields: []string{"country", "margin", "upper(country)"},
+				Computed: []ComputedField{
+					{
+						Name: "margin",
+						Expression: expression.ArithmeticExpression{
+							Type: table.ArithmeticOperationTypeMinus,
+							Left: expression.ColumnExpression{ColumnName: "total_revenue"},
+							Right: expression.ArithmeticExpression{
+								Type:  table.ArithmeticOperationTypeMultiply,
+								Left:  expression.ColumnExpression{ColumnName: "total_cost"},
+								Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(2)},
+							},
+						},
+					},
+					{
+						Name: "upper(country)",
+						Expression: expression.FunctionExpression{
+							Name: "upper",
+							Args: []table.Expression{expression.ColumnExpression{ColumnName: "country"}},
+						},
+					},
+				},
+				Tablename: "sales",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+			},
+		},
+		{
+			name: "with expression of aggregates",
+			stmt: "select region, sum(profit) / count(*) as avg_profit, count(*) from sales group by region;",
+			want: SelectStmt{
+				Fields: []string{"region", "avg_profit", "count(*)"},
+				Computed: []ComputedField{
+					{
+						Name: "avg_profit",
+						Expression: expression.ArithmeticExpression{
+							Type:  table.ArithmeticOperationTypeDivide,
+							Left:  expression.ColumnExpression{ColumnName: "sum(profit)"},
+							Right: expression.ColumnExpression{ColumnName: "count(*)"},
+						},
+					},
+				},
+				Aggregates: []table.Aggregate{
+					{Type: table.AggregateTypeCount, ColumnName: "*"},
+				},
+				HiddenAggregates: []table.Aggregate{
+					{Type: table.AggregateTypeSum, ColumnName: "profit"},
+				},
+				Tablename: "sales",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				GroupBy: []string{"region"},
+			},
+		},
+		{
+			name: "with repeated fields and table asterisk",
+			stmt: "select salary, lastname, salary, employees.* from employees;",
+			want: SelectStmt{
+				Fields:    []string{"salary", "lastname", "salary", "employees.*"},
+				Tablename: "employees",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+			},
+		},
+		{
+			name: "with scalar functions",
+			stmt: "select coalesce(age, 0), case when age > 30 then 'old' end as c, cast(id as string) from people;",
+			want: SelectStmt{
+				Fields: []string{"coalesce(age, 0)", "c", "cast(id as string)"},
+				Computed: []ComputedField{
+					{
+						Name: "coalesce(age, 0)",
+						Expression: expression.FunctionExpression{
+							Name: "coalesce",
+							Args: []table.Expression{
+								expression.ColumnExpression{ColumnName: "age"},
+								expression.ConstExpression{Value: value.NewIntValueFromInt64(0)},
+							},
+						},
+					},
+					{
+						Name: "c",
+						Expression: expression.CaseExpression{
+							Whens: []expression.CaseWhen{
+								{
+									Condition: expression.CompareExpression{
+										Type:  table.CompareOperationTypeMore,
+										Left:  expression.ColumnExpression{ColumnName: "age"},
+										Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(30)},
+									},
+									Result: expression.ConstExpression{Value: value.NewStringValue("old")},
+								},
+							},
+						},
+					},
+					{
+						Name: "cast(id as string)",
+						Expression: expression.CastExpression{
+							Operand: expression.ColumnExpression{ColumnName: "id"},
+							Target:  table.Field{Type: table.FieldTypeString},
+						},
+					},
+				},
+				Tablename: "people",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+			},
+		},
+		{
+			name: "with join",
+			stmt: "select s.country, r.manager from sales s left join regions as r on s.region = r.region join managers on r.manager = managers.name;",
+			want: SelectStmt{
+				Fields:     []string{"s.country", "r.manager"},
+				Tablename:  "sales",
+				TableAlias: "s",
+				Joins: []Join{
+					{
+						Type:      table.JoinTypeLeft,
+						Tablename: "regions",
+						Alias:     "r",
+						On:        []table.JoinKey{{Left: "s.region", Right: "r.region"}},
+					},
+					{
+						Type:      table.JoinTypeInner,
+						Tablename: "managers",
+						On:        []table.JoinKey{{Left: "r.manager", Right: "managers.name"}},
+					},
+				},
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+			},
+		},
+		{
+			name: "with subqueries",
+			stmt: "select name from (select name, age from people) p where age in (select age from kids);",
+			want: SelectStmt{
+				Fields: []string{"name"},
+				Source: &SelectStmt{
+					Fields:    []string{"name", "age"},
+					Tablename: "people",
+					Filter: operation.DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							Type: table.CompareOperationTypeDummy,
+						},
+					},
+				},
+				TableAlias: "p",
+				Subqueries: []Subquery{
+					{
+						Stmt: SelectStmt{
+							Fields:    []string{"age"},
+							Tablename: "kids",
+							Filter: operation.DummyValueOperation{
+								CompareOperation: table.CompareValueOperation{
+									Type: table.CompareOperationTypeDummy,
+								},
+							},
+						},
+						Result: &operation.SubqueryResult{},
+					},
+				},
+				Filter: operation.InSubqueryOperation{
+					ColumnName: "age",
+					Result:     &operation.SubqueryResult{},
+				},
+			},
+		},
+		{
+			name: "with set operations",
+			stmt: "select region from march union all select region from april except select region from may order by region limit 2;",
+			want: SelectStmt{
+				Fields:    []string{"region"},
+				Tablename: "march",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				OrderBy: []table.OrderField{{Name: "region"}},
+				Limit:   &Limit{Count: 2},
+				SetOperations: []SetOperation{
+					{
+						Type: table.SetOperationTypeUnionAll,
+						Stmt: SelectStmt{
+							Fields:    []string{"region"},
+							Tablename: "april",
+							Filter: operation.DummyValueOperation{
+								CompareOperation: table.CompareValueOperation{
+									Type: table.CompareOperationTypeDummy,
+								},
+							},
+						},
+					},
+					{
+						Type: table.SetOperationTypeExcept,
+						Stmt: SelectStmt{
+							Fields:    []string{"region"},
+							Tablename: "may",
+							Filter: operation.DummyValueOperation{
+								CompareOperation: table.CompareValueOperation{
+									Type: table.CompareOperationTypeDummy,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "with distinct",
+			stmt: "select distinct department, count(distinct name) from people group by department limit 3;",
+			want: SelectStmt{
+				Fields:   []string{"department", "count(distinct name)"},
+				Distinct: true,
+				Aggregates: []table.Aggregate{
+					{Type: table.AggregateTypeCount, ColumnName: "name", Distinct: true},
+				},
+				Tablename: "people",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				GroupBy: []string{"department"},
+				Limit:   &Limit{Count: 3},
+			},
+		},
+		{
+			name: "with window functions",
+			stmt: "select country, total_profit - lag(total_profit) over (partition by country order by order_date desc) as diff, row_number() over () from sales;",
+			want: SelectStmt{
+				Fields: []string{"country", "diff", "row_number() over ()"},
+				Computed: []ComputedField{
+					{
+						Name: "diff",
+						Expression: expression.ArithmeticExpression{
+							Type: table.ArithmeticOperationTypeMinus,
+							Left: expression.ColumnExpression{ColumnName: "total_profit"},
+							Right: expression.ColumnExpression{
+								ColumnName: "lag(total_profit) over (partition by country order by order_date desc)",
+							},
+						},
+					},
+				},
+				Windows: []table.Window{
+					{
+						Function:    table.WindowFunctionTypeLag,
+						ColumnName:  "total_profit",
+						Offset:      1,
+						PartitionBy: []string{"country"},
+						OrderBy:     []table.OrderField{{Name: "order_date", Desc: true}},
+					},
+					{Function: table.WindowFunctionTypeRowNumber},
+				},
+				Tablename: "sales",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+			},
+		},
+		{
+			name: "with common tables",
+			stmt: "with top as (select region from sales), big as (select region from top) select region from big;",
+			want: SelectStmt{
+				Fields:    []string{"region"},
+				Tablename: "big",
+				Filter: operation.DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				With: []CommonTable{
+					{
+						Name: "top",
+						Stmt: SelectStmt{
+							Fields:    []string{"region"},
+							Tablename: "sales",
+							Filter: operation.DummyValueOperation{
+								CompareOperation: table.CompareValueOperation{
+									Type: table.CompareOperationTypeDummy,
+								},
+							},
+						},
+					},
+					{
+						Name: "big",
+						Stmt: SelectStmt{
+							Fields:    []string{"region"},
+							Tablename: "top",
+							Filter: operation.DummyValueOperation{
+								CompareOperation: table.CompareValueOperation{
+									Type: table.CompareOperationTypeDummy,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	logger, _ := zap.NewDevelopment()
@@ -61,3 +483,34 @@ func TestMakeSelectStmt(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeSelectStmt_InvalidLimit(t *testing.T) {
+	tests := []struct {
+		stmt    string
+		wantErr string
+	}{
+		{stmt: "select * from sales limit 2.7;", wantErr: "limit should be an integer"},
+		{stmt: "select * from sales limit 10 offset 0.5;", wantErr: "offset should be an integer"},
+		{stmt: "select * from sales limit 1e30;", wantErr: "limit is too large"},
+		{stmt: "select * from sales limit 10 offset 3000000000;", wantErr: "offset is too large"},
+		{stmt: "select * from sales limit 9007199254740993;", wantErr: "limit is too large"},
+	}
+
+	logger, _ := zap.NewDevelopment()
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			tokens, err := scanner.NewScanner(logger).Scan(strings.NewReader(tt.stmt))
+			assert.NoError(t, err)
+			_, err = MakeSelectStmt(tokens)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	tokens, err := scanner.NewScanner(logger).Scan(strings.NewReader(
+		"select * from sales limit 2147483647 offset 2147483647;"))
+	assert.NoError(t, err)
+	got, err := MakeSelectStmt(tokens)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*maxLimit, got.Filter.(operation.LimitOperation).Limit)
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
+)
+
+// SetOperation - запрос, результат которого объединяется с результатом предыдущих частей запроса
+type SetOperation struct {
+	Type table.SetOperationType
+	Stmt SelectStmt
+}
+
+// splitSetOperations делит токены на части запроса по ключевым словам union, intersect и except.
+// Ключевые слова внутри вложенных запросов не учитываются.
+func splitSetOperations(tokens []scanner.Token) ([][]scanner.Token, []table.SetOperationType, error) {
+	var parts [][]scanner.Token
+	var opTypes []table.SetOperationType
+	start := 0
+
+	for i := 0; i < len(tokens); i++ {
+		if isSubqueryStart(tokens, i) {
+			if end := subqueryEnd(tokens, i); end > 0 {
+				i = end
+			}
+
+			continue
+		}
+		if tokens[i].Type() != scanner.TokenTypeKeyword {
+			continue
+		}
+
+		keyword := tokens[i].Value().(string)
+		var opType table.SetOperationType
+		switch keyword {
+		case KeywordUnion:
+			opType = table.SetOperationTypeUnion
+		case KeywordIntersect:
+			opType = table.SetOperationTypeIntersect
+		case KeywordExcept:
+			opType = table.SetOperationTypeExcept
+		default:
+			continue
+		}
+		if i == start {
+			return nil, nil, fmt.Errorf("select should be specified before %s", keyword)
+		}
+		parts = append(parts, tokens[start:i])
+
+		next := i + 1
+		if next < len(tokens) && tokens[next].Type() == scanner.TokenTypeKeyword && tokens[next].Value() == KeywordAll {
+			if opType != table.SetOperationTypeUnion {
+				return nil, nil, fmt.Errorf("%s all is not supported", keyword)
+			}
+			opType = table.SetOperationTypeUnionAll
+			next++
+		}
+		if next == len(tokens) {
+			return nil, nil, fmt.Errorf("select should be specified after %s", opType)
+		}
+		opTypes = append(opTypes, opType)
+		start = next
+		i = next - 1
+	}
+
+	return append(parts, tokens[start:]), opTypes, nil
+}
+
+// moveSetOrder переносит сортировку и limit последней части запроса на весь результат,
+// как это принято для union, intersect и except
+func moveSetOrder(stmt SelectStmt) (SelectStmt, error) {
+	if len(stmt.SetOperations) == 0 {
+		return stmt, nil
+	}
+
+	opType := stmt.SetOperations[0].Type
+	if len(stmt.OrderBy) > 0 || stmt.Limit != nil {
+		return SelectStmt{}, fmt.Errorf("order by and limit should be after the last select of %s", opType)
+	}
+	last := &stmt.SetOperations[len(stmt.SetOperations)-1].Stmt
+	for _, op := range stmt.SetOperations[:len(stmt.SetOperations)-1] {
+		if len(op.Stmt.OrderBy) > 0 || op.Stmt.Limit != nil {
+			return SelectStmt{}, fmt.Errorf("order by and limit should be after the last select of %s", op.Type)
+		}
+	}
+	for _, f := range last.OrderBy {
+		for _, a := range last.HiddenAggregates {
+			if f.Name == a.Name() {
+				return SelectStmt{}, fmt.Errorf("order by after %s should use fields of the result", opType)
+			}
+		}
+	}
+
+	// Ограничение просмотра строк должно применяться к общему результату, а не к последней части
+	if limited, isLimited := last.Filter.(operation.LimitOperation); isLimited && last.Limit != nil {
+		last.Filter = limited.Operation
+	}
+	stmt.OrderBy, stmt.Limit = last.OrderBy, last.Limit
+	last.OrderBy, last.Limit = nil, nil
+
+	return stmt, nil
+}
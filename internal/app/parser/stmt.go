@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+)
+
+// Stmt - разобранный запрос: SelectStmt, CreateTableStmt, CreateViewStmt, DropViewStmt,
+// InsertStmt, UpdateStmt или DeleteStmt
+type Stmt interface {
+	stmt()
+}
+
+func (SelectStmt) stmt()      {}
+func (CreateTableStmt) stmt() {}
+func (CreateViewStmt) stmt()  {}
+func (DropViewStmt) stmt()    {}
+func (InsertStmt) stmt()      {}
+func (UpdateStmt) stmt()      {}
+func (DeleteStmt) stmt()      {}
+
+// MakeStmt определяет вид запроса по первым словам и разбирает его
+func MakeStmt(tokens []scanner.Token) (Stmt, error) {
+	switch {
+	case len(tokens) > 1 && isKeyword(tokens[0], KeywordCreate) && isObject(tokens[1], objectView):
+		return MakeCreateViewStmt(tokens)
+	case len(tokens) > 0 && isKeyword(tokens[0], KeywordCreate):
+		return MakeCreateTableStmt(tokens)
+	case len(tokens) > 0 && isKeyword(tokens[0], KeywordDrop):
+		return MakeDropViewStmt(tokens)
+	case len(tokens) > 0 && isKeyword(tokens[0], KeywordInsert):
+		return MakeInsertStmt(tokens)
+	case len(tokens) > 0 && isKeyword(tokens[0], KeywordUpdate):
+		return MakeUpdateStmt(tokens)
+	case len(tokens) > 0 && isKeyword(tokens[0], KeywordDelete):
+		return MakeDeleteStmt(tokens)
+	default:
+		return MakeSelectStmt(tokens)
+	}
+}
+
+func isKeyword(token scanner.Token, keyword string) bool {
+	return token.Type() == scanner.TokenTypeKeyword && token.Value() == keyword
+}
+
+func isObject(token scanner.Token, object string) bool {
+	name, ok := token.Value().(string)
+
+	return token.Type() == scanner.TokenTypeID && ok && strings.EqualFold(name, object)
+}
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMakeStmt(t *testing.T) {
+	euSales := SelectStmt{
+		AllField:  true,
+		Tablename: "sales",
+		Filter: operation.DummyValueOperation{
+			CompareOperation: table.CompareValueOperation{
+				ColumnName: "region",
+				Type:       table.CompareOperationTypeEqual,
+				Val:        "Europe",
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		stmt    string
+		want    Stmt
+		wantErr error
+	}{
+		{
+			name: "select",
+			stmt: "select * from sales where region = 'Europe';",
+			want: euSales,
+		},
+		{
+			name: "create table",
+			stmt: "CREATE TABLE eu_sales AS SELECT * FROM sales WHERE region = 'Europe';",
+			want: CreateTableStmt{Tablename: "eu_sales", Select: euSales},
+		},
+		{
+			name: "create table with select in brackets",
+			stmt: "create table eu_sales as (select * from sales where region = 'Europe');",
+			want: CreateTableStmt{Tablename: "eu_sales", Select: euSales},
+		},
+		{
+			name: "create view",
+			stmt: "create view eu_sales as select * from sales where region = 'Europe';",
+			want: CreateViewStmt{Viewname: "eu_sales", Select: euSales},
+		},
+		{
+			name: "drop view",
+			stmt: "DROP VIEW eu_sales;",
+			want: DropViewStmt{Viewname: "eu_sales"},
+		},
+		{
+			name:    "drop table",
+			stmt:    "drop table sales;",
+			want:    DropViewStmt{},
+			wantErr: fmt.Errorf("drop view should be in format drop view name"),
+		},
+		{
+			name: "insert",
+			stmt: "insert into regions values ('Oceania', 4), (null, 1 + 2);",
+			want: InsertStmt{
+				Tablename: "regions",
+				Rows: [][]table.Expression{
+					{
+						expression.ConstExpression{Value: value.NewStringValue("Oceania")},
+						expression.ConstExpression{Value: value.NewIntValueFromInt64(4)},
+					},
+					{
+						expression.ConstExpression{Value: value.NewNullValue()},
+						expression.ArithmeticExpression{
+							Type:  table.ArithmeticOperationTypePlus,
+							Left:  expression.ConstExpression{Value: value.NewIntValueFromInt64(1)},
+							Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(2)},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "insert with aggregate function",
+			stmt:    "insert into regions values ('Oceania', count(units));",
+			want:    InsertStmt{},
+			wantErr: fmt.Errorf("aggregate function count is not allowed here"),
+		},
+		{
+			name: "update",
+			stmt: "UPDATE sales SET units = units + 1, region = 'EU' WHERE region = 'Europe';",
+			want: UpdateStmt{
+				Tablename: "sales",
+				Assignments: []table.Assignment{
+					{ColumnName: "units", Expression: expression.ArithmeticExpression{
+						Type:  table.ArithmeticOperationTypePlus,
+						Left:  expression.ColumnExpression{ColumnName: "units"},
+						Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(1)},
+					}},
+					{ColumnName: "region", Expression: expression.ConstExpression{Value: value.NewStringValue("EU")}},
+				},
+				Filter: euSales.Filter,
+			},
+		},
+		{
+			name:    "update without assignment",
+			stmt:    "update sales set units where region = 'Europe';",
+			want:    UpdateStmt{},
+			wantErr: fmt.Errorf("set section should be in format field = expr, ..."),
+		},
+		{
+			name: "delete",
+			stmt: "delete from sales where region = 'Europe';",
+			want: DeleteStmt{Tablename: "sales", Filter: euSales.Filter},
+		},
+		{
+			name:    "update with empty where",
+			stmt:    "update sales set units = 1 where;",
+			want:    UpdateStmt{},
+			wantErr: fmt.Errorf("condition should be specified after where"),
+		},
+		{
+			name:    "delete with empty where",
+			stmt:    "delete from sales where;",
+			want:    DeleteStmt{},
+			wantErr: fmt.Errorf("condition should be specified after where"),
+		},
+		{
+			name:    "delete with order by",
+			stmt:    "delete from sales order by units;",
+			want:    DeleteStmt{},
+			wantErr: fmt.Errorf("delete supports only table name and where section"),
+		},
+		{
+			name:    "create table without as",
+			stmt:    "create table eu_sales select * from sales;",
+			want:    CreateTableStmt{},
+			wantErr: fmt.Errorf("create table should be in format create table name as select ..."),
+		},
+		{
+			name:    "create table without query",
+			stmt:    "create table eu_sales as;",
+			want:    CreateTableStmt{},
+			wantErr: fmt.Errorf("create table should be in format create table name as select ..."),
+		},
+		{
+			name:    "create view with empty brackets",
+			stmt:    "create view eu_sales as ();",
+			want:    CreateViewStmt{},
+			wantErr: fmt.Errorf("create view should be in format create view name as select ..."),
+		},
+	}
+	logger := zap.NewNop()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := scanner.NewScanner(logger).Scan(strings.NewReader(tt.stmt))
+			assert.Equal(t, err, nil)
+			got, err := MakeStmt(tokens)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
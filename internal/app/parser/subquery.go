@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table/operation"
+)
+
+// isSubqueryStart проверяет, что с токена i начинается вложенный запрос: отметка скобки, за которой идёт select
+func isSubqueryStart(tokens []scanner.Token, i int) bool {
+	return i+1 < len(tokens) &&
+		tokens[i].Type() == scanner.TokenTypeOpenCurlyBracket &&
+		tokens[i+1].Type() == scanner.TokenTypeKeyword &&
+		tokens[i+1].Value() == KeywordSelect
+}
+
+// subqueryEnd возвращает индекс закрывающей скобки вложенного запроса или описания окна, которые начинаются
+// с токена start. Отмечаются только закрывающие скобки вложенных запросов и окон, поэтому достаточно считать их пары.
+func subqueryEnd(tokens []scanner.Token, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch {
+		case isSubqueryStart(tokens, i), isWindowStart(tokens, i-1):
+			depth++
+		case tokens[i].Type() == scanner.TokenTypeClosedCurlyBracket:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// appendSubquery использует вложенный запрос как таблицу секций from и join или как список значений in
+func (b *selectStmtBuilder) appendSubquery(stmt SelectStmt) error {
+	switch b.lastKeyword {
+	case KeywordFrom, KeywordJoin:
+		tablename, _, source := b.currentTable()
+		if *tablename != "" || *source != nil {
+			return fmt.Errorf("tablename should be specified once")
+		}
+		*source = &stmt
+	case KeywordWhere, KeywordHaving:
+		sub := Subquery{Stmt: stmt, Result: &operation.SubqueryResult{}}
+		b.subqueries = append(b.subqueries, sub)
+		token := scanner.NewToken(sub, scanner.TokenTypeSubquery)
+		if b.lastKeyword == KeywordWhere {
+			b.conditions = append(b.conditions, token)
+		} else {
+			b.having = append(b.having, token)
+		}
+	default:
+		return fmt.Errorf("subquery is not allowed in %s section", b.lastKeyword)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+// isWindowStart проверяет, что с токена i начинается описание окна: over и отметка скобки
+func isWindowStart(tokens []scanner.Token, i int) bool {
+	return i >= 0 && i+1 < len(tokens) &&
+		tokens[i].Type() == scanner.TokenTypeKeyword &&
+		tokens[i].Value() == KeywordOver &&
+		tokens[i+1].Type() == scanner.TokenTypeOpenCurlyBracket
+}
+
+// isWindowCall проверяет, что токен i - вызов функции, за которым следует описание окна
+func isWindowCall(tokens []scanner.Token, i int) bool {
+	return tokens[i].Type() == scanner.TokenTypeFunction && isWindowStart(tokens, i+1)
+}
+
+// appendWindow добавляет в секцию select оконную функцию. Её значение вычисляется в отдельное поле,
+// поэтому в выражениях она используется как поле таблицы.
+func (b *selectStmtBuilder) appendWindow(token scanner.Token, spec []scanner.Token) error {
+	if b.lastKeyword != KeywordSelect || b.expectAlias {
+		return fmt.Errorf("window function %s is allowed only in select section", token.Value())
+	}
+
+	var args []table.Expression
+	if last := len(b.items) - 1; token.Args() == 1 && last >= 0 && b.items[last].alias == "" &&
+		b.items[last].expr == (expression.ColumnExpression{ColumnName: allFieldsSign}) {
+		// count(*) считает строки, поэтому '*' не раскрывается в поля
+		args = []table.Expression{b.items[last].expr}
+		b.items = b.items[:last]
+	} else {
+		var err error
+		if args, err = b.popOperands(token, token.Args()); err != nil {
+			return err
+		}
+	}
+
+	w, err := makeWindow(token, args)
+	if err != nil {
+		return err
+	}
+	if w.PartitionBy, w.OrderBy, err = makeWindowSpec(spec); err != nil {
+		return err
+	}
+
+	found := false
+	for _, existing := range b.windows {
+		found = found || existing.Name() == w.Name()
+	}
+	if !found {
+		b.windows = append(b.windows, w)
+	}
+	b.items = append(b.items, selectItem{expr: expression.ColumnExpression{ColumnName: w.Name()}})
+
+	return nil
+}
+
+// makeWindow проверяет аргументы оконной функции
+func makeWindow(token scanner.Token, args []table.Expression) (table.Window, error) {
+	w := table.Window{Function: table.WindowFunctionType(token.Value().(string))}
+	if token.Distinct() {
+		return table.Window{}, fmt.Errorf("distinct is not supported in window functions")
+	}
+
+	switch w.Function {
+	case table.WindowFunctionTypeRowNumber, table.WindowFunctionTypeRank:
+		if len(args) != 0 {
+			return table.Window{}, fmt.Errorf("function %s should be called without arguments", w.Function)
+		}
+
+		return w, nil
+	case table.WindowFunctionTypeLag, table.WindowFunctionTypeLead:
+		if len(args) == 0 || len(args) > 3 {
+			return table.Window{}, fmt.Errorf("function %s should be in format %s(field[, offset[, default]])", w.Function, w.Function)
+		}
+		w.Offset = 1
+		if len(args) > 1 {
+			c, _ := args[1].(expression.ConstExpression)
+			offset, valid := c.Value.(value.IntValue)
+			if !valid || offset.Int64() < 0 || offset.Int64() > math.MaxInt32 {
+				return table.Window{}, fmt.Errorf("offset of function %s should be a non-negative integer", w.Function)
+			}
+			w.Offset = int(offset.Int64())
+		}
+		if len(args) > 2 {
+			c, valid := args[2].(expression.ConstExpression)
+			if !valid {
+				return table.Window{}, fmt.Errorf("default of function %s should be a constant", w.Function)
+			}
+			w.Default = c.Value
+		}
+	default:
+		aggregateType, err := parseAggregateType(string(w.Function))
+		if err != nil {
+			return table.Window{}, fmt.Errorf("function %s cannot be used with over", w.Function)
+		}
+		if len(args) != 1 {
+			return table.Window{}, fmt.Errorf("function %s requires an argument", w.Function)
+		}
+		column, valid := args[0].(expression.ColumnExpression)
+		if valid && column.ColumnName == allFieldsSign && aggregateType != table.AggregateTypeCount {
+			return table.Window{}, fmt.Errorf("function %s cannot be applied to '*'", aggregateType)
+		}
+	}
+
+	column, valid := args[0].(expression.ColumnExpression)
+	if !valid {
+		return table.Window{}, fmt.Errorf("argument of function %s should be a field", w.Function)
+	}
+	w.ColumnName = column.ColumnName
+
+	return w, nil
+}
+
+// makeWindowSpec разбирает описание окна: [partition by поля] [order by поля [asc|desc]]
+func makeWindowSpec(tokens []scanner.Token) ([]string, []table.OrderField, error) {
+	var partitionBy []string
+	var orderBy []table.OrderField
+	section := ""
+	invalidFormat := fmt.Errorf("window should be in format over ([partition by fields] [order by fields])")
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Type() == scanner.TokenTypeID {
+			switch section {
+			case KeywordPartition:
+				partitionBy = append(partitionBy, token.Value().(string))
+			case KeywordOrder:
+				orderBy = append(orderBy, table.OrderField{Name: token.Value().(string)})
+			default:
+				return nil, nil, invalidFormat
+			}
+
+			continue
+		}
+		if token.Type() != scanner.TokenTypeKeyword {
+			return nil, nil, invalidFormat
+		}
+
+		switch keyword := token.Value().(string); keyword {
+		case KeywordPartition, KeywordOrder:
+			next := i + 1
+			if next == len(tokens) || tokens[next].Value() != KeywordBy {
+				return nil, nil, fmt.Errorf("%s should be followed by by", keyword)
+			}
+			if section == KeywordOrder || section == keyword || (section == KeywordPartition && len(partitionBy) == 0) {
+				return nil, nil, invalidFormat
+			}
+			section = keyword
+			i = next
+		case KeywordAsc, KeywordDesc:
+			if section != KeywordOrder || len(orderBy) == 0 {
+				return nil, nil, fmt.Errorf("%s should be after field in order by of window", keyword)
+			}
+			orderBy[len(orderBy)-1].Desc = keyword == KeywordDesc
+		default:
+			return nil, nil, invalidFormat
+		}
+	}
+	if section == KeywordPartition && len(partitionBy) == 0 || section == KeywordOrder && len(orderBy) == 0 {
+		return nil, nil, fmt.Errorf("fields should be specified after %s by in window", section)
+	}
+
+	return partitionBy, orderBy, nil
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/scanner"
+)
+
+// CommonTable - результат запроса секции with, к которому можно обратиться по наименованию вместо таблицы
+type CommonTable struct {
+	Name string
+	Stmt SelectStmt
+}
+
+// splitWith разбирает секцию with в начале запроса: with name as (select ...)[, ...] select ...
+// и возвращает токены запроса, который следует за ней
+func splitWith(tokens []scanner.Token) ([]CommonTable, []scanner.Token, error) {
+	if len(tokens) == 0 || tokens[0].Type() != scanner.TokenTypeKeyword || tokens[0].Value() != KeywordWith {
+		return nil, tokens, nil
+	}
+
+	var ret []CommonTable
+	names := make(map[string]struct{})
+	i := 1
+	for i < len(tokens) && tokens[i].Type() == scanner.TokenTypeID {
+		if i+1 == len(tokens) || tokens[i+1].Type() != scanner.TokenTypeKeyword ||
+			tokens[i+1].Value() != KeywordAs || !isSubqueryStart(tokens, i+2) {
+			return nil, nil, fmt.Errorf("with should be in format with name as (select ...) select ...")
+		}
+		end := subqueryEnd(tokens, i+2)
+		if end < 0 {
+			return nil, nil, fmt.Errorf("subquery should be closed by bracket")
+		}
+
+		name := tokens[i].Value().(string)
+		if _, found := names[name]; found {
+			return nil, nil, fmt.Errorf("table '%s' is specified twice in with", name)
+		}
+		names[name] = struct{}{}
+		stmt, err := MakeSelectStmt(tokens[i+3 : end])
+		if err != nil {
+			return nil, nil, err
+		}
+		ret = append(ret, CommonTable{Name: name, Stmt: stmt})
+		i = end + 1
+	}
+	if len(ret) == 0 {
+		return nil, nil, fmt.Errorf("with should be in format with name as (select ...) select ...")
+	}
+	if i == len(tokens) || tokens[i].Type() != scanner.TokenTypeKeyword || tokens[i].Value() != KeywordSelect {
+		return nil, nil, fmt.Errorf("select should be after with")
+	}
+
+	return ret, tokens[i:], nil
+}
@@ -14,12 +14,62 @@ const (
 	KeywordSelect = "select"
 	KeywordFrom   = "from"
 	KeywordWhere  = "where"
+	KeywordGroup  = "group"
+	KeywordHaving = "having"
+	KeywordOrder  = "order"
+	KeywordBy     = "by"
+	KeywordAsc    = "asc"
+	KeywordDesc   = "desc"
+	KeywordLimit  = "limit"
+	KeywordOffset = "offset"
+	KeywordAs     = "as"
+	KeywordJoin   = "join"
+	KeywordInner  = "inner"
+	KeywordLeft   = "left"
+	KeywordOuter  = "outer"
+	KeywordOn     = "on"
+)
+
+// Ключевые слова операций над результатами запросов
+const (
+	KeywordUnion     = "union"
+	KeywordAll       = "all"
+	KeywordIntersect = "intersect"
+	KeywordExcept    = "except"
+	KeywordDistinct  = "distinct"
+	KeywordOver      = "over"
+	KeywordPartition = "partition"
+	KeywordWith      = "with"
+	KeywordCreate    = "create"
+	KeywordDrop      = "drop"
+	KeywordInsert    = "insert"
+	KeywordInto      = "into"
+	KeywordValues    = "values"
+	KeywordUpdate    = "update"
+	KeywordSet       = "set"
+	KeywordDelete    = "delete"
+)
+
+const (
+	KeywordIn      = "in"
+	KeywordBetween = "between"
+	KeywordIs      = "is"
+	KeywordIsNot   = "is not"
+	KeywordNull    = "null"
+	KeywordTrue    = "true"
+	KeywordFalse   = "false"
+	KeywordCase    = "case"
+	KeywordWhen    = "when"
+	KeywordThen    = "then"
+	KeywordElse    = "else"
+	KeywordEnd     = "end"
+	KeywordCast    = "cast"
 )
 
 var (
 	regexpNumber  = regexp.MustCompile(`^[0-9]+(.[0-9]+)?$`)
 	regexpID      = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9_]*|\*`)
-	regexpKeyword = regexp.MustCompile(`select|from|where`)
+	regexpKeyword = regexp.MustCompile(`^(select|from|where|group|having|order|by|asc|desc|limit|offset|as|join|inner|left|outer|on|union|all|intersect|except|distinct|over|partition|with|create|drop|insert|into|values|update|set|delete)$`)
 )
 
 func NewTokenizer() *Tokenizer {
@@ -32,48 +82,277 @@ func NewTokenizer() *Tokenizer {
 type Tokenizer struct {
 	tokens []Token
 	stack  []Token
+	// calls - открытые скобки и выражения case, в которых считаются аргументы
+	calls []call
+	// last - последний добавленный токен, от которого зависит значение некоторых операций
+	last Token
+	// values - после ключевого слова values скобки верхнего уровня содержат строки значений
+	values bool
+}
+
+// call описывает открытую скобку или выражение case
+type call struct {
+	// function - наименование функции, если скобка открывает её аргументы
+	function string
+	// separators - число разделителей аргументов
+	separators int
+	// clause - последнее ключевое слово выражения case
+	clause string
+	// marked - начало скобки отмечено в списке токенов
+	marked bool
+	// subquery - скобка содержит вложенный запрос, её конец тоже отмечается в списке токенов
+	subquery bool
+	// distinct - повторяющиеся значения аргумента функции учитываются один раз
+	distinct bool
+	// window - скобка содержит описание окна, её конец тоже отмечается в списке токенов
+	window bool
+	// row - скобка содержит строку значений insert, её конец тоже отмечается в списке токенов
+	row bool
 }
 
 func (t *Tokenizer) AddToTokens(token Token) error {
+	defer func() {
+		t.last = token
+	}()
+
 	switch token.Type() {
-	case TokenTypeKeyword, TokenTypeID, TokenTypeString, TokenTypeNumber, TokenTypeUnknown:
+	case TokenTypeKeyword:
+		// 'as' внутри cast отделяет значение от типа так же, как запятая
+		if token.Value() == KeywordAs && len(t.calls) > 0 && t.calls[len(t.calls)-1].function == KeywordCast {
+			return t.separateArgs()
+		}
+		if token.Value() == KeywordDistinct && len(t.calls) > 0 &&
+			t.calls[len(t.calls)-1].function != "" && t.calls[len(t.calls)-1].function != KeywordCase {
+			return t.distinctArgs()
+		}
+		// over относится к вызову функции перед ним, поэтому не завершает выражение
+		if token.Value() == KeywordOver {
+			t.tokens = append(t.tokens, token)
+
+			return nil
+		}
+		if token.Value() == KeywordSelect && t.last.Type() == TokenTypeOpenCurlyBracket {
+			t.openSubquery()
+		}
+		// Ключевое слово завершает предыдущую секцию, поэтому выталкиваем
+		// накопленные операции до открывающейся скобки
+		t.popOperations(func(Token) bool { return true })
 		t.tokens = append(t.tokens, token)
-	default:
-		// Помещаем операции с большим или равным приоритетом в список токенов
-		i := len(t.stack) - 1
-		for i >= 0 && t.stack[i].priority >= token.priority && t.stack[i].Type() != TokenTypeOpenCurlyBracket {
-			t.tokens = append(t.tokens, t.stack[i])
-			i = i - 1
+		t.values = token.Value() == KeywordValues
+	case TokenTypeCase:
+		return t.addCase(token)
+	case TokenTypeFunction:
+		// Функция попадает в список токенов после своих аргументов
+		t.stack = append(t.stack, token)
+	case TokenTypeID, TokenTypeString, TokenTypeNumber, TokenTypeNull, TokenTypeBool, TokenTypeUnknown:
+		t.tokens = append(t.tokens, token)
+	case TokenTypeComma:
+		// Запятая завершает выражение так же, как ключевое слово, но не попадает в список токенов
+		if len(t.calls) > 0 {
+			return t.separateArgs()
 		}
+		t.popOperations(func(Token) bool { return true })
+	case TokenTypeOpNegate:
+		t.stack = append(t.stack, token)
+	case TokenTypeOpNot:
+		// 'is not' является одной операцией
+		if t.last.Type() == TokenTypeOpIs {
+			t.stack[len(t.stack)-1] = NewToken(KeywordIsNot, TokenTypeOpIsNot)
 
-		// Помещаем операции до открывающейся скобки в список токенов
-		if token.Type() == TokenTypeClosedCurlyBracket {
-			var openBracketFound bool
+			return nil
+		}
+		// Унарная операция не имеет левого операнда, поэтому ничего не выталкивает
+		t.stack = append(t.stack, token)
+	case TokenTypeOpAnd:
+		if t.completeBetween() {
+			return nil
+		}
+		t.pushOperation(token)
+	case TokenTypeOpenCurlyBracket:
+		// Скобка после in открывает список значений, начало которого отмечается в списке токенов
+		var c call
+		if t.last.Type() == TokenTypeOpIn {
+			t.tokens = append(t.tokens, token)
+			c.marked = true
+		}
+		// Скобка после over открывает описание окна, начало и конец которого отмечаются в списке токенов
+		if t.last.Type() == TokenTypeKeyword && t.last.Value() == KeywordOver {
+			t.tokens = append(t.tokens, token)
+			c.marked, c.window = true, true
+		}
+		// Скобка верхнего уровня после values открывает строку значений
+		if t.values && len(t.calls) == 0 {
+			t.tokens = append(t.tokens, token)
+			c.marked, c.row = true, true
+		}
+		if t.last.Type() == TokenTypeFunction {
+			c.function = t.last.Value().(string)
+		}
+		t.calls = append(t.calls, c)
+		t.stack = append(t.stack, token)
+	case TokenTypeClosedCurlyBracket:
+		return t.closeBracket()
+	default:
+		t.pushOperation(token)
+	}
 
-			for i >= 0 {
-				openBracketFound = t.stack[i].Type() == TokenTypeOpenCurlyBracket
-				if openBracketFound {
-					i = i - 1
+	return nil
+}
 
-					break
-				}
-				t.tokens = append(t.tokens, t.stack[i])
-				i = i - 1
-			}
-			if !openBracketFound {
-				return fmt.Errorf("not found opened curly bracket")
-			}
-		}
-		t.stack = t.stack[:i+1]
-		if token.Type() != TokenTypeClosedCurlyBracket {
-			t.stack = append(t.stack, token)
+// popOperations перемещает операции из стека в список токенов, пока выполняется условие
+// и не встретилась открывающаяся скобка
+func (t *Tokenizer) popOperations(condition func(Token) bool) {
+	i := len(t.stack) - 1
+	for i >= 0 && t.stack[i].Type() != TokenTypeOpenCurlyBracket && condition(t.stack[i]) {
+		t.tokens = append(t.tokens, t.stack[i])
+		i = i - 1
+	}
+	t.stack = t.stack[:i+1]
+}
+
+func (t *Tokenizer) pushOperation(token Token) {
+	// Помещаем операции с большим или равным приоритетом в список токенов
+	t.popOperations(func(op Token) bool {
+		return op.priority >= token.priority
+	})
+	t.stack = append(t.stack, token)
+}
+
+// completeBetween связывает 'and' с ожидающей его операцией between
+func (t *Tokenizer) completeBetween() bool {
+	between := NewToken(KeywordBetween, TokenTypeOpBetween)
+	t.popOperations(func(op Token) bool {
+		return op.priority > between.priority
+	})
+	if len(t.stack) == 0 || t.stack[len(t.stack)-1].Type() != TokenTypeOpBetween {
+		return false
+	}
+	t.stack[len(t.stack)-1] = NewToken(KeywordBetween, TokenTypeOpBetweenAnd)
+
+	return true
+}
+
+func (t *Tokenizer) closeBracket() error {
+	// Помещаем операции до открывающейся скобки в список токенов
+	t.popOperations(func(Token) bool { return true })
+	if len(t.stack) == 0 {
+		return fmt.Errorf("not found opened curly bracket")
+	}
+	c := t.calls[len(t.calls)-1]
+	if c.function == KeywordCase {
+		return fmt.Errorf("case should be closed by end")
+	}
+	t.calls = t.calls[:len(t.calls)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	if c.subquery || c.window || c.row {
+		t.tokens = append(t.tokens, NewToken(")", TokenTypeClosedCurlyBracket))
+
+		return nil
+	}
+
+	if len(t.stack) > 0 && t.stack[len(t.stack)-1].Type() == TokenTypeFunction {
+		// Пустые скобки означают вызов без аргументов
+		args := c.separators + 1
+		if t.last.Type() == TokenTypeOpenCurlyBracket {
+			args = 0
 		}
+		token := NewFunctionToken(c.function, args)
+		token.distinct = c.distinct
+		t.tokens = append(t.tokens, token)
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+
+	return nil
+}
+
+// openSubquery отмечает в списке токенов начало вложенного запроса, который начинается сразу после скобки
+func (t *Tokenizer) openSubquery() {
+	c := &t.calls[len(t.calls)-1]
+	if !c.marked {
+		t.tokens = append(t.tokens, t.last)
+		c.marked = true
+	}
+	c.subquery = true
+}
+
+// distinctArgs обрабатывает distinct в начале аргументов функции: count(distinct region)
+func (t *Tokenizer) distinctArgs() error {
+	c := &t.calls[len(t.calls)-1]
+	if t.last.Type() != TokenTypeOpenCurlyBracket {
+		return fmt.Errorf("distinct should be the first word in arguments of %s", c.function)
+	}
+	c.distinct = true
 
+	return nil
+}
+
+// separateArgs завершает очередной аргумент вызова функции
+func (t *Tokenizer) separateArgs() error {
+	if t.calls[len(t.calls)-1].function == KeywordCase {
+		return fmt.Errorf("arguments of case should be separated by when, then and else")
 	}
+	t.popOperations(func(Token) bool { return true })
+	t.calls[len(t.calls)-1].separators++
 
 	return nil
 }
 
+// addCase обрабатывает ключевые слова выражения case. Выражение записывается как вызов функции case,
+// аргументы которой - пары условие-значение и значение else, если оно указано
+func (t *Tokenizer) addCase(token Token) error {
+	keyword := token.Value().(string)
+	if keyword == KeywordCase {
+		t.calls = append(t.calls, call{function: KeywordCase, clause: KeywordCase})
+		t.stack = append(t.stack, NewToken(keyword, TokenTypeOpenCurlyBracket))
+
+		return nil
+	}
+	if len(t.calls) == 0 || t.calls[len(t.calls)-1].function != KeywordCase {
+		return fmt.Errorf("%s should be inside case", keyword)
+	}
+
+	c := &t.calls[len(t.calls)-1]
+	allowed := map[string][]string{
+		KeywordWhen: {KeywordCase, KeywordThen},
+		KeywordThen: {KeywordWhen},
+		KeywordElse: {KeywordThen},
+		KeywordEnd:  {KeywordThen, KeywordElse},
+	}
+	valid := false
+	for _, prev := range allowed[keyword] {
+		valid = valid || c.clause == prev
+	}
+	// Каждая часть выражения case должна быть непустой
+	if !valid || (c.clause != KeywordCase && !t.afterOperand()) {
+		return fmt.Errorf("unexpected %s in case", keyword)
+	}
+
+	t.popOperations(func(Token) bool { return true })
+	if c.clause != KeywordCase {
+		c.separators++
+	}
+	c.clause = keyword
+	if keyword == KeywordEnd {
+		t.stack = t.stack[:len(t.stack)-1]
+		t.tokens = append(t.tokens, NewFunctionToken(KeywordCase, c.separators))
+		t.calls = t.calls[:len(t.calls)-1]
+	}
+
+	return nil
+}
+
+// afterOperand сообщает, что последний токен завершает операнд, поэтому следующий знак - бинарная операция
+func (t *Tokenizer) afterOperand() bool {
+	switch t.last.Type() {
+	case TokenTypeID, TokenTypeString, TokenTypeNumber, TokenTypeNull, TokenTypeBool, TokenTypeClosedCurlyBracket:
+		return true
+	case TokenTypeCase:
+		return t.last.Value() == KeywordEnd
+	}
+
+	return false
+}
+
 func (t *Tokenizer) GetTokens() []Token {
 	for i := len(t.stack) - 1; i >= 0; i-- {
 		t.tokens = append(t.tokens, t.stack[i])
@@ -94,6 +373,8 @@ type Scanner struct {
 	buf       strings.Builder
 	tokenizer *Tokenizer
 	logger    *zap.Logger
+	// sign - первый символ знака сравнения, который может оказаться двухсимвольным
+	sign rune
 }
 
 func (p *Scanner) Scan(reader io.RuneReader) ([]Token, error) {
@@ -106,6 +387,30 @@ func (p *Scanner) Scan(reader io.RuneReader) ([]Token, error) {
 			return nil, fmt.Errorf("unexpected error when reading statement: %w", err)
 		}
 
+		// ==== Обработка знаков сравнения
+		if r == '=' || r == '>' || r == '<' || r == '!' {
+			if err = p.handleCompareSign(r); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+		if err = p.flushCompareSign(); err != nil {
+			return nil, err
+		}
+
+		// Сокращённая запись regexp
+		if r == '~' {
+			if err = p.flushBuffer(); err != nil {
+				return nil, err
+			}
+			if err = p.tokenizer.AddToTokens(NewToken(string(r), TokenTypeOpRegexp)); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
 		// Конец выражения
 		if r == ';' {
 			if err = p.flushBuffer(); err != nil {
@@ -133,18 +438,13 @@ func (p *Scanner) Scan(reader io.RuneReader) ([]Token, error) {
 			continue
 		}
 
-		// ==== Обработка знаков сравнения
-		if r == '=' || r == '>' || r == '<' {
-			if err = p.handleCompareSign(r); err != nil {
-				return nil, err
-			}
-
-			continue
-		}
-
 		// ==== Обработка скобок
 		if r == '(' || r == ')' {
-			if err = p.flushBuffer(); err != nil {
+			flush := p.flushBuffer
+			if r == '(' {
+				flush = p.flushFunction
+			}
+			if err = flush(); err != nil {
 				return nil, err
 			}
 			tokenType := TokenTypeOpenCurlyBracket
@@ -160,8 +460,17 @@ func (p *Scanner) Scan(reader io.RuneReader) ([]Token, error) {
 			continue
 		}
 
+		// ==== Обработка арифметических операций и разделителя выражений
+		if r == '+' || r == '-' || r == '*' || r == '/' || r == ',' {
+			if err = p.handleSign(r); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
 		// ==== Обработка символов ключевых слов, наименования полей и таблиц
-		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '*' {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' {
 			p.buf.WriteRune(unicode.ToLower(r))
 
 			continue
@@ -183,6 +492,23 @@ func (p *Scanner) flushBuffer() error {
 	return nil
 }
 
+// flushFunction считает идентификатор, за которым сразу следует скобка, вызовом функции
+func (p *Scanner) flushFunction() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	token := ParseTokenType(p.buf.String())
+	if token.Type() == TokenTypeID && token.Value() != "*" {
+		token = NewToken(token.Value(), TokenTypeFunction)
+	}
+	if err := p.tokenizer.AddToTokens(token); err != nil {
+		return err
+	}
+	p.buf.Reset()
+
+	return nil
+}
+
 func (p *Scanner) extractString(reader io.RuneReader) error {
 	var hasPrevRuneEscape bool
 
@@ -249,22 +575,86 @@ func (p *Scanner) extractID(reader io.RuneReader) error {
 	}
 }
 
+// handleSign различает знаки, значение которых зависит от предыдущего токена:
+// '*' после операнда - умножение, иначе - все поля; '-' после операнда - вычитание, иначе - смена знака
+func (p *Scanner) handleSign(r rune) error {
+	// Все поля таблицы: 't.*'
+	if r == '*' && strings.HasSuffix(p.buf.String(), ".") {
+		p.buf.WriteRune(r)
+
+		return nil
+	}
+	if err := p.flushBuffer(); err != nil {
+		return err
+	}
+
+	var token Token
+	switch {
+	case r == ',':
+		token = NewToken(string(r), TokenTypeComma)
+	case r == '+':
+		token = NewToken(string(r), TokenTypeOpPlus)
+	case r == '/':
+		token = NewToken(string(r), TokenTypeOpDivide)
+	case r == '*' && p.tokenizer.afterOperand():
+		token = NewToken(string(r), TokenTypeOpMultiply)
+	case r == '*':
+		token = NewToken(string(r), TokenTypeID)
+	case p.tokenizer.afterOperand():
+		token = NewToken(string(r), TokenTypeOpMinus)
+	default:
+		token = NewToken(string(r), TokenTypeOpNegate)
+	}
+
+	return p.tokenizer.AddToTokens(token)
+}
+
+// handleCompareSign откладывает первый символ знака сравнения до получения следующего символа
 func (p *Scanner) handleCompareSign(r rune) error {
+	if p.sign == 0 {
+		if err := p.flushBuffer(); err != nil {
+			return err
+		}
+		p.sign = r
+
+		return nil
+	}
+
+	sign := string([]rune{p.sign, r})
+	p.sign = 0
+
+	var tokenType TokenType
+	switch sign {
+	case "<=":
+		tokenType = TokenTypeOpLessOrEqual
+	case ">=":
+		tokenType = TokenTypeOpMoreOrEqual
+	case "!=", "<>":
+		tokenType = TokenTypeOpNotEqual
+	default:
+		return fmt.Errorf("unknown compare operation '%s'", sign)
+	}
+
+	return p.tokenizer.AddToTokens(NewToken(sign, tokenType))
+}
+
+// flushCompareSign добавляет отложенный односимвольный знак сравнения
+func (p *Scanner) flushCompareSign() error {
+	if p.sign == 0 {
+		return nil
+	}
+
 	tokenType := TokenTypeOpEqual
-	switch r {
+	switch p.sign {
 	case '>':
 		tokenType = TokenTypeOpMore
 	case '<':
 		tokenType = TokenTypeOpLess
+	case '!':
+		return fmt.Errorf("unknown compare operation '!'")
 	}
-	if err := p.flushBuffer(); err != nil {
-		return err
-	}
-	p.buf.WriteRune(r)
-	if err := p.tokenizer.AddToTokens(NewToken(p.buf.String(), tokenType)); err != nil {
-		return err
-	}
-	p.buf.Reset()
+	sign := string(p.sign)
+	p.sign = 0
 
-	return nil
+	return p.tokenizer.AddToTokens(NewToken(sign, tokenType))
 }
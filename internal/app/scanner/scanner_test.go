@@ -350,6 +350,832 @@ func TestScan(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "reserved words in double quotes",
+			reader: strings.NewReader(`SELECT "set", values FROM "values";`),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "set",
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordValues,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordFrom,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "values",
+				},
+			},
+		},
+		{
+			name:   "order by after where",
+			reader: strings.NewReader("WHERE col_1 > 2 ORDER BY col_1 DESC;"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordWhere,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "col_1",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     2.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpMore,
+					value:     ">",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordOrder,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordBy,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "col_1",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordDesc,
+					priority:  0,
+				},
+			},
+		},
+		{
+			name:   "function call",
+			reader: strings.NewReader("SELECT COUNT(*), SUM(total) FROM table;"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "*",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     "count",
+					priority:  0,
+					args:      1,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "total",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     "sum",
+					priority:  0,
+					args:      1,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordFrom,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "table",
+					priority:  0,
+				},
+			},
+		},
+		{
+			name:   "two-character compare operations",
+			reader: strings.NewReader("WHERE a>=1 OR b <> 'x' OR c!=2 OR d <= 3;"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordWhere,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     1.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpMoreOrEqual,
+					value:     ">=",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "x",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpNotEqual,
+					value:     "<>",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     2.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpNotEqual,
+					value:     "!=",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "d",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     3.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpLessOrEqual,
+					value:     "<=",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+			},
+		},
+		{
+			name:   "not operation",
+			reader: strings.NewReader("WHERE NOT a = 1 AND NOT (b = 2 OR c = 3);"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordWhere,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     1.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpEqual,
+					value:     "=",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpNot,
+					value:     "not",
+					priority:  2,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     2.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpEqual,
+					value:     "=",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     3.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpEqual,
+					value:     "=",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+				{
+					tokenType: TokenTypeOpNot,
+					value:     "not",
+					priority:  2,
+				},
+				{
+					tokenType: TokenTypeOpAnd,
+					value:     "and",
+					priority:  2,
+				},
+			},
+		},
+		{
+			name:   "pattern operations",
+			reader: strings.NewReader("WHERE a LIKE 'x%' OR b ILIKE '_y' OR c~'z';"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordWhere,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "x%",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpLike,
+					value:     "like",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "_y",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpILike,
+					value:     "ilike",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "z",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpRegexp,
+					value:     "~",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+			},
+		},
+		{
+			name:   "in, between and is not null",
+			reader: strings.NewReader("WHERE a IN ('x', 'y') AND b BETWEEN 1 AND 5 OR c IS NOT NULL;"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordWhere,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpenCurlyBracket,
+					value:     "(",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "x",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "y",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpIn,
+					value:     KeywordIn,
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     1.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     5.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpBetweenAnd,
+					value:     KeywordBetween,
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpAnd,
+					value:     "and",
+					priority:  2,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNull,
+					value:     KeywordNull,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpIsNot,
+					value:     KeywordIsNot,
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeOpOr,
+					value:     "or",
+					priority:  1,
+				},
+			},
+		},
+		{
+			name:   "arithmetic and alias",
+			reader: strings.NewReader("SELECT a - -b * 2 AS c, d;"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpNegate,
+					value:     "-",
+					priority:  7,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     2.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpMultiply,
+					value:     "*",
+					priority:  6,
+				},
+				{
+					tokenType: TokenTypeOpMinus,
+					value:     "-",
+					priority:  5,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordAs,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "d",
+					priority:  0,
+				},
+			},
+		},
+		{
+			name:   "function arguments, cast and case",
+			reader: strings.NewReader("SELECT substr(a, 1 + 2), now(), cast(b AS int), CASE WHEN c > 1 THEN 'x' ELSE 'y' END;"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     1.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     2.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpPlus,
+					value:     "+",
+					priority:  5,
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     "substr",
+					priority:  0,
+					args:      2,
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     "now",
+					priority:  0,
+					args:      0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "int",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     KeywordCast,
+					priority:  0,
+					args:      2,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     1.0,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpMore,
+					value:     ">",
+					priority:  3,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "x",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "y",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     KeywordCase,
+					priority:  0,
+					args:      3,
+				},
+			},
+		},
+		{
+			name:   "subqueries",
+			reader: strings.NewReader("FROM (SELECT a FROM t) s WHERE a IN (SELECT b FROM u);"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordFrom,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpenCurlyBracket,
+					value:     "(",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordFrom,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "t",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeClosedCurlyBracket,
+					value:     ")",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "s",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordWhere,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeOpenCurlyBracket,
+					value:     "(",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordFrom,
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "u",
+					priority:  0,
+				},
+				{
+					tokenType: TokenTypeClosedCurlyBracket,
+					value:     ")",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeOpIn,
+					value:     KeywordIn,
+					priority:  3,
+				},
+			},
+		},
+		{
+			name:   "distinct",
+			reader: strings.NewReader("SELECT DISTINCT region, COUNT(DISTINCT country);"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordDistinct,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "region",
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "country",
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     "count",
+					args:      1,
+					distinct:  true,
+				},
+			},
+		},
+		{
+			name:   "window function in expression",
+			reader: strings.NewReader("SELECT a - LAG(a) OVER (PARTITION BY b ORDER BY c DESC);"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordSelect,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "a",
+				},
+				{
+					tokenType: TokenTypeFunction,
+					value:     "lag",
+					args:      1,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordOver,
+				},
+				{
+					tokenType: TokenTypeOpenCurlyBracket,
+					value:     "(",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordPartition,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     "by",
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "b",
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     "order",
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     "by",
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "c",
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     "desc",
+				},
+				{
+					tokenType: TokenTypeClosedCurlyBracket,
+					value:     ")",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeOpMinus,
+					value:     "-",
+					priority:  5,
+				},
+			},
+		},
+		{
+			name:   "insert values",
+			reader: strings.NewReader("INSERT INTO t VALUES (1 + 2, 'a'), (3, 'b');"),
+			want: []Token{
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordInsert,
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordInto,
+				},
+				{
+					tokenType: TokenTypeID,
+					value:     "t",
+				},
+				{
+					tokenType: TokenTypeKeyword,
+					value:     KeywordValues,
+				},
+				{
+					tokenType: TokenTypeOpenCurlyBracket,
+					value:     "(",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     1.0,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     2.0,
+				},
+				{
+					tokenType: TokenTypeOpPlus,
+					value:     "+",
+					priority:  5,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "a",
+				},
+				{
+					tokenType: TokenTypeClosedCurlyBracket,
+					value:     ")",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeOpenCurlyBracket,
+					value:     "(",
+					priority:  4,
+				},
+				{
+					tokenType: TokenTypeNumber,
+					value:     3.0,
+				},
+				{
+					tokenType: TokenTypeString,
+					value:     "b",
+				},
+				{
+					tokenType: TokenTypeClosedCurlyBracket,
+					value:     ")",
+					priority:  4,
+				},
+			},
+		},
 	}
 
 	logger, _ := zap.NewDevelopment()
@@ -17,6 +17,29 @@ const (
 	TokenTypeOpOr               TokenType = iota
 	TokenTypeOpenCurlyBracket   TokenType = iota
 	TokenTypeClosedCurlyBracket TokenType = iota
+	TokenTypeFunction           TokenType = iota
+	TokenTypeOpNotEqual         TokenType = iota
+	TokenTypeOpLessOrEqual      TokenType = iota
+	TokenTypeOpMoreOrEqual      TokenType = iota
+	TokenTypeOpNot              TokenType = iota
+	TokenTypeOpLike             TokenType = iota
+	TokenTypeOpILike            TokenType = iota
+	TokenTypeOpRegexp           TokenType = iota
+	TokenTypeOpIn               TokenType = iota
+	TokenTypeOpBetween          TokenType = iota
+	TokenTypeOpBetweenAnd       TokenType = iota
+	TokenTypeOpIs               TokenType = iota
+	TokenTypeOpIsNot            TokenType = iota
+	TokenTypeNull               TokenType = iota
+	TokenTypeBool               TokenType = iota
+	TokenTypeOpPlus             TokenType = iota
+	TokenTypeOpMinus            TokenType = iota
+	TokenTypeOpMultiply         TokenType = iota
+	TokenTypeOpDivide           TokenType = iota
+	TokenTypeOpNegate           TokenType = iota
+	TokenTypeComma              TokenType = iota
+	TokenTypeCase               TokenType = iota
+	TokenTypeSubquery           TokenType = iota // вложенный запрос, которым парсер заменяет его токены
 )
 
 func NewToken(value interface{}, tokenType TokenType) Token {
@@ -26,12 +49,46 @@ func NewToken(value interface{}, tokenType TokenType) Token {
 		priority = 4
 	case TokenTypeClosedCurlyBracket:
 		priority = 4
+	case TokenTypeOpNegate:
+		priority = 7
+	case TokenTypeOpMultiply:
+		priority = 6
+	case TokenTypeOpDivide:
+		priority = 6
+	case TokenTypeOpPlus:
+		priority = 5
+	case TokenTypeOpMinus:
+		priority = 5
 	case TokenTypeOpMore:
 		priority = 3
 	case TokenTypeOpLess:
 		priority = 3
 	case TokenTypeOpEqual:
 		priority = 3
+	case TokenTypeOpNotEqual:
+		priority = 3
+	case TokenTypeOpLessOrEqual:
+		priority = 3
+	case TokenTypeOpMoreOrEqual:
+		priority = 3
+	case TokenTypeOpLike:
+		priority = 3
+	case TokenTypeOpILike:
+		priority = 3
+	case TokenTypeOpRegexp:
+		priority = 3
+	case TokenTypeOpIn:
+		priority = 3
+	case TokenTypeOpBetween:
+		priority = 3
+	case TokenTypeOpBetweenAnd:
+		priority = 3
+	case TokenTypeOpIs:
+		priority = 3
+	case TokenTypeOpIsNot:
+		priority = 3
+	case TokenTypeOpNot:
+		priority = 2
 	case TokenTypeOpAnd:
 		priority = 2
 	case TokenTypeOpOr:
@@ -45,6 +102,8 @@ func NewToken(value interface{}, tokenType TokenType) Token {
 	}
 }
 
+const maxExactFloatInt = 1 << 53
+
 func ParseTokenType(value string) Token {
 	if value == "and" {
 		return NewToken(value, TokenTypeOpAnd)
@@ -52,7 +111,41 @@ func ParseTokenType(value string) Token {
 	if value == "or" {
 		return NewToken(value, TokenTypeOpOr)
 	}
+	if value == "not" {
+		return NewToken(value, TokenTypeOpNot)
+	}
+	if value == KeywordIn {
+		return NewToken(value, TokenTypeOpIn)
+	}
+	if value == KeywordBetween {
+		return NewToken(value, TokenTypeOpBetween)
+	}
+	if value == KeywordIs {
+		return NewToken(value, TokenTypeOpIs)
+	}
+	if value == KeywordNull {
+		return NewToken(value, TokenTypeNull)
+	}
+	if value == KeywordCase || value == KeywordWhen || value == KeywordThen || value == KeywordElse || value == KeywordEnd {
+		return NewToken(value, TokenTypeCase)
+	}
+	if value == KeywordTrue || value == KeywordFalse {
+		return NewToken(value == KeywordTrue, TokenTypeBool)
+	}
+	if value == "like" {
+		return NewToken(value, TokenTypeOpLike)
+	}
+	if value == "ilike" {
+		return NewToken(value, TokenTypeOpILike)
+	}
+	if value == "regexp" {
+		return NewToken(value, TokenTypeOpRegexp)
+	}
 	if matched := regexpNumber.MatchString(value); matched {
+		// Целые числа, которые float64 не может представить точно, сохраняются как int64
+		if val, err := strconv.ParseInt(value, 10, 64); err == nil && val > maxExactFloatInt {
+			return NewToken(val, TokenTypeNumber)
+		}
 		// можно игнорировать ошибку, поскольку значение проверено регулярным выражением
 		val, _ := strconv.ParseFloat(value, 64)
 
@@ -68,10 +161,22 @@ func ParseTokenType(value string) Token {
 	return NewToken(value, TokenTypeUnknown)
 }
 
+// NewFunctionToken создаёт токен вызова функции с указанным числом аргументов
+func NewFunctionToken(name string, args int) Token {
+	token := NewToken(name, TokenTypeFunction)
+	token.args = args
+
+	return token
+}
+
 type Token struct {
 	tokenType TokenType
 	priority  int
 	value     interface{}
+	// args - число аргументов вызова функции
+	args int
+	// distinct - в вызове функции указано distinct перед аргументом
+	distinct bool
 }
 
 func (t *Token) Value() interface{} {
@@ -81,3 +186,11 @@ func (t *Token) Value() interface{} {
 func (t *Token) Type() TokenType {
 	return t.tokenType
 }
+
+func (t *Token) Args() int {
+	return t.args
+}
+
+func (t *Token) Distinct() bool {
+	return t.distinct
+}
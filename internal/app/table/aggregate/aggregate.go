@@ -0,0 +1,399 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+const allRows = "*"
+
+// Apply вычисляет агрегатные функции по строкам rowIndexes и возвращает таблицу из одной строки
+func Apply(ctx context.Context, t table.Table, rowIndexes []int, aggregates []table.Aggregate) (table.Table, error) {
+	return GroupBy(ctx, t, rowIndexes, nil, aggregates)
+}
+
+// GroupBy группирует строки rowIndexes по значениям полей keys и вычисляет агрегатные функции
+// для каждой группы. Результирующая таблица содержит поля группировки и значения функций.
+func GroupBy(
+	ctx context.Context,
+	t table.Table,
+	rowIndexes []int,
+	keys []string,
+	aggregates []table.Aggregate,
+) (table.Table, error) {
+	cols := make([]table.Column, 0, len(keys)+len(aggregates))
+	keyCols := make([]table.Column, 0, len(keys))
+	for _, key := range keys {
+		col, err := t.GetColumnByName(key)
+		if err != nil {
+			return table.Table{}, err
+		}
+		keyCols = append(keyCols, col)
+		cols = append(cols, table.Column{Field: col.Field})
+	}
+
+	// Заранее проверяем применимость функций к полям, даже если групп не окажется
+	for _, a := range aggregates {
+		acc, err := newAccumulator(t, a)
+		if err != nil {
+			return table.Table{}, err
+		}
+		field := acc.field()
+		field.Name, field.Table = a.Name(), ""
+		cols = append(cols, table.Column{Field: field})
+	}
+
+	groups, err := makeGroups(ctx, t, rowIndexes, keyCols, aggregates)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	for _, g := range groups {
+		for i, col := range keyCols {
+			cols[i].Values = append(cols[i].Values, col.Values[g.rowIndex])
+		}
+		for i, acc := range g.accumulators {
+			cols[len(keyCols)+i].Values = append(cols[len(keyCols)+i].Values, acc.result())
+		}
+	}
+
+	return table.NewTable(t.Name, cols), nil
+}
+
+// Running вычисляет функцию a нарастающим итогом: i-е значение учитывает строки rowIndexes[:i+1].
+// Также возвращается описание поля с результатом функции без наименования.
+func Running(ctx context.Context, t table.Table, rowIndexes []int, a table.Aggregate) ([]table.Value, table.Field, error) {
+	acc, err := newAccumulator(t, a)
+	if err != nil {
+		return nil, table.Field{}, err
+	}
+
+	ret := make([]table.Value, 0, len(rowIndexes))
+	for _, index := range rowIndexes {
+		select {
+		case <-ctx.Done():
+			return nil, table.Field{}, ctx.Err()
+		default:
+		}
+		if err := acc.add(index); err != nil {
+			return nil, table.Field{}, err
+		}
+		ret = append(ret, acc.result())
+	}
+
+	return ret, acc.field(), nil
+}
+
+type group struct {
+	// rowIndex - индекс первой строки группы, из которой берутся значения полей группировки
+	rowIndex     int
+	accumulators []accumulator
+}
+
+func makeGroups(
+	ctx context.Context,
+	t table.Table,
+	rowIndexes []int,
+	keyCols []table.Column,
+	aggregates []table.Aggregate,
+) ([]group, error) {
+	var groups []group
+	groupIndexes := make(map[string]int)
+
+	for _, index := range rowIndexes {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Отсутствующие значения попадают в одну группу
+		key, _ := table.RowKey(keyCols, index)
+		i, found := groupIndexes[key]
+		if !found {
+			accumulators, err := newAccumulators(t, aggregates)
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, group{rowIndex: index, accumulators: accumulators})
+			i = len(groups) - 1
+			groupIndexes[key] = i
+		}
+
+		for _, acc := range groups[i].accumulators {
+			if err := acc.add(index); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Без группировки функции вычисляются даже для пустого набора строк
+	if len(keyCols) == 0 && len(groups) == 0 {
+		accumulators, err := newAccumulators(t, aggregates)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group{accumulators: accumulators})
+	}
+
+	return groups, nil
+}
+
+func newAccumulators(t table.Table, aggregates []table.Aggregate) ([]accumulator, error) {
+	ret := make([]accumulator, 0, len(aggregates))
+	for _, a := range aggregates {
+		acc, err := newAccumulator(t, a)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, acc)
+	}
+
+	return ret, nil
+}
+
+type accumulator interface {
+	add(rowIndex int) error
+	result() table.Value
+	// field возвращает описание поля с результатом функции без наименования
+	field() table.Field
+}
+
+func newAccumulator(t table.Table, a table.Aggregate) (accumulator, error) {
+	if a.Distinct && a.Type != table.AggregateTypeCount {
+		return nil, fmt.Errorf("distinct is supported only in function %s", table.AggregateTypeCount)
+	}
+	if a.ColumnName == allRows {
+		if a.Distinct {
+			return nil, fmt.Errorf("distinct cannot be applied to '*'")
+		}
+		if a.Type != table.AggregateTypeCount {
+			return nil, fmt.Errorf("function %s cannot be applied to '*'", a.Type)
+		}
+
+		return &countAccumulator{}, nil
+	}
+
+	col, err := t.GetColumnByName(a.ColumnName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a.Type {
+	case table.AggregateTypeCount:
+		acc := &countAccumulator{col: &col}
+		if a.Distinct {
+			acc.seen = make(map[string]struct{})
+		}
+
+		return acc, nil
+	case table.AggregateTypeSum, table.AggregateTypeAvg:
+		switch col.Field.Type {
+		case table.FieldTypeNumber:
+			return &sumAccumulator{col: col, avg: a.Type == table.AggregateTypeAvg}, nil
+		case table.FieldTypeInt, table.FieldTypeDecimal:
+			return &exactSumAccumulator{col: col, avg: a.Type == table.AggregateTypeAvg}, nil
+		}
+
+		return nil, fmt.Errorf("function %s is applicable only to number fields, '%s' is not a number", a.Type, col.Field.Name)
+	case table.AggregateTypeMin, table.AggregateTypeMax:
+		return &extremumAccumulator{col: col, max: a.Type == table.AggregateTypeMax}, nil
+	}
+
+	return nil, fmt.Errorf("unknown aggregate function: %s", a.Type)
+}
+
+// countAccumulator считает все строки для '*' и только заполненные значения для поля
+type countAccumulator struct {
+	col   *table.Column
+	count int
+	// seen - уже посчитанные значения, если повторяющиеся значения учитываются один раз
+	seen map[string]struct{}
+}
+
+func (a *countAccumulator) add(rowIndex int) error {
+	if a.col == nil {
+		a.count++
+
+		return nil
+	}
+	key, valid := table.HashKey(a.col.Values[rowIndex])
+	if !valid {
+		return nil
+	}
+	if a.seen != nil {
+		if _, found := a.seen[key]; found {
+			return nil
+		}
+		a.seen[key] = struct{}{}
+	}
+	a.count++
+
+	return nil
+}
+
+func (a *countAccumulator) result() table.Value {
+	return value.NewIntValueFromInt64(int64(a.count))
+}
+
+func (a *countAccumulator) field() table.Field {
+	return table.Field{Type: table.FieldTypeInt}
+}
+
+type sumAccumulator struct {
+	col   table.Column
+	avg   bool
+	sum   float64
+	count int
+}
+
+func (a *sumAccumulator) add(rowIndex int) error {
+	if a.col.Values[rowIndex].Value() == nil {
+		return nil
+	}
+	val, valid := a.col.Values[rowIndex].Value().(float64)
+	if !valid {
+		return fmt.Errorf("invalid number value in field '%s': '%v'", a.col.Field.Name, a.col.Values[rowIndex])
+	}
+	a.sum += val
+	a.count++
+
+	return nil
+}
+
+// result возвращает NULL, если не было ни одного значения, как и для avg, min и max
+func (a *sumAccumulator) result() table.Value {
+	if a.count == 0 {
+		return value.NewNullValue()
+	}
+	if !a.avg {
+		return value.NewNumberValueFromFloat(a.sum)
+	}
+
+	return value.NewNumberValueFromFloat(a.sum / float64(a.count))
+}
+
+func (a *sumAccumulator) field() table.Field {
+	return table.Field{Type: table.FieldTypeNumber, Nullable: true}
+}
+
+// exactSumAccumulator суммирует поля int и decimal без потери точности
+type exactSumAccumulator struct {
+	col table.Column
+	avg bool
+	// sum - сумма в единицах младшего разряда поля
+	sum   int64
+	count int
+}
+
+func (a *exactSumAccumulator) add(rowIndex int) error {
+	var units int64
+	switch val := a.col.Values[rowIndex].(type) {
+	case value.NullValue:
+		return nil
+	case value.IntValue:
+		units = val.Int64()
+	case value.DecimalValue:
+		units = val.Units()
+		// Значения с другим числом знаков после запятой приводятся к масштабу поля
+		if val.Scale() != a.col.Field.Scale {
+			rescaled, err := value.NewDecimalValueFromRat(val.Value().(*big.Rat), a.col.Field.Scale)
+			if err != nil {
+				return fmt.Errorf("sum of field '%s': %w", a.col.Field.Name, err)
+			}
+			units = rescaled.Units()
+		}
+	default:
+		return fmt.Errorf("invalid number value in field '%s': '%v'", a.col.Field.Name, val)
+	}
+
+	sum := a.sum + units
+	if (units > 0 && sum < a.sum) || (units < 0 && sum > a.sum) {
+		return fmt.Errorf("sum of field '%s' is out of range", a.col.Field.Name)
+	}
+	a.sum = sum
+	a.count++
+
+	return nil
+}
+
+func (a *exactSumAccumulator) result() table.Value {
+	if a.count == 0 {
+		return value.NewNullValue()
+	}
+	scale := a.col.Field.Scale
+	if !a.avg {
+		if a.col.Field.Type == table.FieldTypeInt {
+			return value.NewIntValueFromInt64(a.sum)
+		}
+
+		return value.NewDecimalValueFromUnits(a.sum, scale)
+	}
+
+	avg := new(big.Rat).SetFrac(big.NewInt(a.sum), new(big.Int).Mul(big.NewInt(int64(a.count)), big.NewInt(value.Pow10(scale))))
+	if a.col.Field.Type == table.FieldTypeInt {
+		f, _ := avg.Float64()
+
+		return value.NewNumberValueFromFloat(f)
+	}
+	// Среднее не превышает по модулю наибольшее слагаемое, поэтому помещается в decimal
+	ret, _ := value.NewDecimalValueFromRat(avg, scale)
+
+	return ret
+}
+
+func (a *exactSumAccumulator) field() table.Field {
+	field := table.Field{Type: a.col.Field.Type, Scale: a.col.Field.Scale, Nullable: true}
+	if a.avg && a.col.Field.Type == table.FieldTypeInt {
+		field.Type = table.FieldTypeNumber
+	}
+
+	return field
+}
+
+type extremumAccumulator struct {
+	col     table.Column
+	max     bool
+	current table.Value
+}
+
+func (a *extremumAccumulator) add(rowIndex int) error {
+	val := a.col.Values[rowIndex]
+	if val.Value() == nil {
+		return nil
+	}
+	if a.current == nil {
+		a.current = val
+
+		return nil
+	}
+
+	res, err := table.CompareValues(val, a.current)
+	if err != nil {
+		return err
+	}
+	if (a.max && res > 0) || (!a.max && res < 0) {
+		a.current = val
+	}
+
+	return nil
+}
+
+func (a *extremumAccumulator) result() table.Value {
+	if a.current != nil {
+		return a.current
+	}
+
+	return value.NewNullValue()
+}
+
+func (a *extremumAccumulator) field() table.Field {
+	field := a.col.Field
+	field.Nullable = true
+
+	return field
+}
@@ -0,0 +1,311 @@
+package aggregate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTable() table.Table {
+	names := []string{"Mike", "Anna", "John", "Kate"}
+	departments := []string{"IT", "Sales", "IT", "Sales"}
+	salaries := []string{"100", "250.5", "50", "300"}
+
+	nameValues := make([]table.Value, 0, len(names))
+	departmentValues := make([]table.Value, 0, len(departments))
+	salaryValues := make([]table.Value, 0, len(salaries))
+	bonusValues := make([]table.Value, 0, len(names))
+	for i := range names {
+		bonusValues = append(bonusValues, value.NewNullValue())
+		nameValues = append(nameValues, value.NewStringValue(names[i]))
+		departmentValues = append(departmentValues, value.NewStringValue(departments[i]))
+		salary, _ := value.NewNumberValue(salaries[i])
+		salaryValues = append(salaryValues, salary)
+	}
+
+	return table.NewTable("employees", []table.Column{
+		{
+			Field:  table.Field{Name: "name", Type: table.FieldTypeString},
+			Values: nameValues,
+		},
+		{
+			Field:  table.Field{Name: "department", Type: table.FieldTypeString},
+			Values: departmentValues,
+		},
+		{
+			Field:  table.Field{Name: "salary", Type: table.FieldTypeNumber},
+			Values: salaryValues,
+		},
+		{
+			Field:  table.Field{Name: "bonus", Type: table.FieldTypeInt, Nullable: true},
+			Values: bonusValues,
+		},
+	})
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		rowIndexes []int
+		aggregates []table.Aggregate
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "all functions",
+			rowIndexes: []int{0, 1, 2, 3},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "*"},
+				{Type: table.AggregateTypeSum, ColumnName: "salary"},
+				{Type: table.AggregateTypeAvg, ColumnName: "salary"},
+				{Type: table.AggregateTypeMin, ColumnName: "salary"},
+				{Type: table.AggregateTypeMax, ColumnName: "name"},
+			},
+			want: []string{"4", "700.5", "175.125", "50", "Mike"},
+		},
+		{
+			name:       "subset of rows",
+			rowIndexes: []int{1, 3},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "name"},
+				{Type: table.AggregateTypeMin, ColumnName: "name"},
+			},
+			want: []string{"2", "Anna"},
+		},
+		{
+			name:       "no rows",
+			rowIndexes: nil,
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "*"},
+				{Type: table.AggregateTypeSum, ColumnName: "salary"},
+				{Type: table.AggregateTypeAvg, ColumnName: "salary"},
+				{Type: table.AggregateTypeMax, ColumnName: "name"},
+			},
+			want: []string{"0", "NULL", "NULL", "NULL"},
+		},
+		{
+			name:       "all null group",
+			rowIndexes: []int{0, 1, 2, 3},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "bonus"},
+				{Type: table.AggregateTypeSum, ColumnName: "bonus"},
+				{Type: table.AggregateTypeAvg, ColumnName: "bonus"},
+				{Type: table.AggregateTypeMin, ColumnName: "bonus"},
+			},
+			want: []string{"0", "NULL", "NULL", "NULL"},
+		},
+		{
+			name:       "count distinct",
+			rowIndexes: []int{0, 1, 2, 3},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "department", Distinct: true},
+				{Type: table.AggregateTypeCount, ColumnName: "department"},
+			},
+			want: []string{"2", "4"},
+		},
+		{
+			name:       "sum distinct",
+			rowIndexes: []int{0, 1},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeSum, ColumnName: "salary", Distinct: true},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "sum of strings",
+			rowIndexes: []int{0, 1},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeSum, ColumnName: "name"},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "unknown column",
+			rowIndexes: []int{0, 1},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeMax, ColumnName: "age"},
+			},
+			wantErr: true,
+		},
+	}
+
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(ctx, tbl, tt.rowIndexes, tt.aggregates)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 1, got.RowCount())
+
+			values := make([]string, 0, len(got.Columns))
+			for _, col := range got.Columns {
+				values = append(values, col.Values[0].String())
+				if col.Values[0].Value() == nil {
+					assert.True(t, col.Field.Nullable, col.Field.Name)
+				}
+			}
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		rowIndexes []int
+		keys       []string
+		aggregates []table.Aggregate
+		want       [][]string
+	}{
+		{
+			name:       "group by department",
+			rowIndexes: []int{0, 1, 2, 3},
+			keys:       []string{"department"},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "*"},
+				{Type: table.AggregateTypeSum, ColumnName: "salary"},
+			},
+			want: [][]string{
+				{"IT", "2", "150"},
+				{"Sales", "2", "550.5"},
+			},
+		},
+		{
+			name:       "without aggregates",
+			rowIndexes: []int{3, 2, 1},
+			keys:       []string{"department"},
+			want: [][]string{
+				{"Sales"},
+				{"IT"},
+			},
+		},
+		{
+			name:       "no rows",
+			rowIndexes: nil,
+			keys:       []string{"department"},
+			aggregates: []table.Aggregate{
+				{Type: table.AggregateTypeCount, ColumnName: "*"},
+			},
+			want: [][]string{},
+		},
+	}
+
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GroupBy(ctx, tbl, tt.rowIndexes, tt.keys, tt.aggregates)
+			assert.NoError(t, err)
+
+			rows := make([][]string, 0, got.RowCount())
+			for rowIndex := 0; rowIndex < got.RowCount(); rowIndex++ {
+				row := make([]string, 0, len(got.Columns))
+				for _, col := range got.Columns {
+					row = append(row, col.Values[rowIndex].String())
+				}
+				rows = append(rows, row)
+			}
+			assert.Equal(t, tt.want, rows)
+		})
+	}
+}
+
+func TestApplyWithNulls(t *testing.T) {
+	salary, _ := value.NewNumberValue("100")
+	tbl := table.NewTable("employees", []table.Column{
+		{
+			Field: table.Field{Name: "salary", Type: table.FieldTypeNumber, Nullable: true},
+			Values: []table.Value{
+				value.NewNullValue(),
+				salary,
+				value.NewNullValue(),
+			},
+		},
+	})
+
+	got, err := Apply(context.Background(), tbl, tbl.RowIndexes(), []table.Aggregate{
+		{Type: table.AggregateTypeCount, ColumnName: "*"},
+		{Type: table.AggregateTypeCount, ColumnName: "salary"},
+		{Type: table.AggregateTypeAvg, ColumnName: "salary"},
+		{Type: table.AggregateTypeMin, ColumnName: "salary"},
+	})
+	assert.NoError(t, err)
+
+	values := make([]string, 0, len(got.Columns))
+	for _, col := range got.Columns {
+		values = append(values, col.Values[0].String())
+	}
+	assert.Equal(t, []string{"3", "1", "100", "100"}, values)
+	for _, col := range got.Columns[:2] {
+		assert.Equal(t, table.Field{Name: col.Field.Name, Type: table.FieldTypeInt}, col.Field)
+		assert.IsType(t, value.IntValue{}, col.Values[0])
+	}
+}
+
+func TestApplyExactSum(t *testing.T) {
+	amounts := []string{"0.10", "0.20", "0.05"}
+	amountValues := make([]table.Value, 0, len(amounts))
+	for _, amount := range amounts {
+		val, _ := value.NewDecimalValue(amount, 2)
+		amountValues = append(amountValues, val)
+	}
+	tbl := table.NewTable("payments", []table.Column{
+		{
+			Field:  table.Field{Name: "amount", Type: table.FieldTypeDecimal, Scale: 2},
+			Values: amountValues,
+		},
+		{
+			Field: table.Field{Name: "id", Type: table.FieldTypeInt},
+			Values: []table.Value{
+				value.NewIntValueFromInt64(9007199254740993),
+				value.NewIntValueFromInt64(1),
+				value.NewIntValueFromInt64(2),
+			},
+		},
+	})
+
+	got, err := Apply(context.Background(), tbl, tbl.RowIndexes(), []table.Aggregate{
+		{Type: table.AggregateTypeSum, ColumnName: "amount"},
+		{Type: table.AggregateTypeAvg, ColumnName: "amount"},
+		{Type: table.AggregateTypeSum, ColumnName: "id"},
+	})
+	assert.NoError(t, err)
+
+	values := make([]string, 0, len(got.Columns))
+	for _, col := range got.Columns {
+		values = append(values, col.Values[0].String())
+	}
+	assert.Equal(t, []string{"0.35", "0.12", "9007199254740996"}, values)
+	assert.Equal(t, table.FieldTypeDecimal, got.Columns[1].Field.Type)
+	assert.Equal(t, 2, got.Columns[1].Field.Scale)
+}
+
+func TestApplyExactSum_MixedScale(t *testing.T) {
+	prices := []table.Value{
+		value.NewDecimalValueFromUnits(125, 2),
+		value.NewDecimalValueFromUnits(250, 2),
+		value.NewDecimalValueFromUnits(12500, 4),
+		value.NewDecimalValueFromUnits(25000, 4),
+	}
+	tbl := table.NewTable("prices", []table.Column{
+		{Field: table.Field{Name: "price", Type: table.FieldTypeDecimal, Scale: 2}, Values: prices},
+	})
+
+	got, err := Apply(context.Background(), tbl, tbl.RowIndexes(), []table.Aggregate{
+		{Type: table.AggregateTypeSum, ColumnName: "price"},
+		{Type: table.AggregateTypeAvg, ColumnName: "price"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "7.50", got.Columns[0].Values[0].String())
+	assert.Equal(t, "1.88", got.Columns[1].Values[0].String())
+}
@@ -0,0 +1,7 @@
+package table
+
+// Assignment - новое значение поля ColumnName, которое вычисляется по выражению
+type Assignment struct {
+	ColumnName string
+	Expression Expression
+}
@@ -0,0 +1,163 @@
+package dml
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+)
+
+// Insert добавляет строки в конец таблицы. Выражения строк не могут ссылаться на поля,
+// их значения приводятся к типам полей таблицы.
+func Insert(ctx context.Context, t table.Table, rows [][]table.Expression) (table.Table, error) {
+	cols := copyColumns(t)
+	empty := table.NewTable(t.Name, nil)
+	for _, row := range rows {
+		select {
+		case <-ctx.Done():
+			return table.Table{}, ctx.Err()
+		default:
+		}
+
+		if len(row) != len(cols) {
+			return table.Table{}, fmt.Errorf("table '%s' has %d fields, but %d values are specified",
+				t.Name, len(cols), len(row))
+		}
+		for i, expr := range row {
+			expr, err := compile(empty, expr, cols[i].Field)
+			if err != nil {
+				return table.Table{}, err
+			}
+			val, err := evaluate(empty, 0, expr, cols[i].Field)
+			if err != nil {
+				return table.Table{}, err
+			}
+			cols[i].Values = append(cols[i].Values, val)
+		}
+	}
+
+	return newTable(t, cols), nil
+}
+
+// Update заменяет значения полей в строках rowIndexes. Все выражения вычисляются по значениям строки до изменения.
+func Update(
+	ctx context.Context,
+	t table.Table,
+	rowIndexes []int,
+	assignments []table.Assignment,
+) (table.Table, error) {
+	cols := copyColumns(t)
+	targets := make([]int, 0, len(assignments))
+	exprs := make([]table.Expression, 0, len(assignments))
+	for _, a := range assignments {
+		i, err := columnIndex(t, a.ColumnName)
+		if err != nil {
+			return table.Table{}, err
+		}
+		for _, target := range targets {
+			if target == i {
+				return table.Table{}, fmt.Errorf("field '%s' is assigned twice", a.ColumnName)
+			}
+		}
+		expr, err := compile(t, a.Expression, cols[i].Field)
+		if err != nil {
+			return table.Table{}, err
+		}
+		targets = append(targets, i)
+		exprs = append(exprs, expr)
+	}
+
+	for _, rowIndex := range rowIndexes {
+		select {
+		case <-ctx.Done():
+			return table.Table{}, ctx.Err()
+		default:
+		}
+
+		for j, expr := range exprs {
+			field := cols[targets[j]].Field
+			val, err := evaluate(t, rowIndex, expr, field)
+			if err != nil {
+				return table.Table{}, err
+			}
+			cols[targets[j]].Values[rowIndex] = val
+		}
+	}
+
+	return newTable(t, cols), nil
+}
+
+// Delete удаляет строки rowIndexes
+func Delete(ctx context.Context, t table.Table, rowIndexes []int) (table.Table, error) {
+	deleted := make(map[int]struct{}, len(rowIndexes))
+	for _, rowIndex := range rowIndexes {
+		deleted[rowIndex] = struct{}{}
+	}
+	kept := make([]int, 0, t.RowCount()-len(deleted))
+	for _, rowIndex := range t.RowIndexes() {
+		if _, found := deleted[rowIndex]; !found {
+			kept = append(kept, rowIndex)
+		}
+	}
+
+	ret, err := t.GetSubTableByIndexes(ctx, kept)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	return newTable(t, ret.Columns), nil
+}
+
+// compile проверяет, что значение выражения можно привести к типу поля field, и компилирует выражение
+func compile(t table.Table, expr table.Expression, field table.Field) (table.Expression, error) {
+	if _, err := (expression.CastExpression{Operand: expr, Target: field}).Field(t); err != nil {
+		return nil, fmt.Errorf("invalid value of field '%s': %w", field.Name, err)
+	}
+
+	return expression.Compile(expr, t)
+}
+
+// evaluate вычисляет выражение и проверяет, что его значение можно записать в поле field без округления
+func evaluate(t table.Table, rowIndex int, expr table.Expression, field table.Field) (table.Value, error) {
+	val, err := expr.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	val, err = expression.ConvertExact(val, field)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of field '%s': %w", field.Name, err)
+	}
+	if val.Value() == nil && !field.Nullable {
+		return nil, fmt.Errorf("field '%s' cannot be null", field.Name)
+	}
+
+	return val, nil
+}
+
+func columnIndex(t table.Table, name string) (int, error) {
+	for i, col := range t.Columns {
+		if col.Field.Name == name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("field '%s' not found in table '%s'", name, t.Name)
+}
+
+// copyColumns копирует значения, чтобы изменения не затронули таблицу t и полученные из неё результаты запросов
+func copyColumns(t table.Table) []table.Column {
+	cols := make([]table.Column, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		cols = append(cols, table.Column{Field: col.Field, Values: append([]table.Value{}, col.Values...)})
+	}
+
+	return cols
+}
+
+func newTable(t table.Table, cols []table.Column) table.Table {
+	ret := table.NewTable(t.Name, cols)
+	ret.Source = t.Source
+
+	return ret
+}
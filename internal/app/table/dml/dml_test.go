@@ -0,0 +1,199 @@
+package dml
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRegions() table.Table {
+	return table.NewTable("regions", []table.Column{
+		{
+			Field: table.Field{Name: "region", Type: table.FieldTypeString},
+			Values: []table.Value{
+				value.NewStringValue("Europe"), value.NewStringValue("Asia"), value.NewStringValue("Africa"),
+			},
+		},
+		{
+			Field: table.Field{Name: "units", Type: table.FieldTypeInt, Nullable: true},
+			Values: []table.Value{
+				value.NewIntValueFromInt64(10), value.NewNullValue(), value.NewIntValueFromInt64(7),
+			},
+		},
+	})
+}
+
+func rows(t table.Table) [][]string {
+	ret := make([][]string, 0, t.RowCount())
+	for _, rowIndex := range t.RowIndexes() {
+		row := make([]string, 0, len(t.Columns))
+		for _, col := range t.Columns {
+			row = append(row, col.Values[rowIndex].String())
+		}
+		ret = append(ret, row)
+	}
+
+	return ret
+}
+
+func constant(val table.Value) table.Expression {
+	return expression.ConstExpression{Value: val}
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]table.Expression
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "values are converted to field types",
+			rows: [][]table.Expression{
+				{constant(value.NewStringValue("Oceania")), constant(value.NewStringValue("4"))},
+				{constant(value.NewStringValue("America")), constant(value.NewNullValue())},
+			},
+			want: [][]string{
+				{"Europe", "10"}, {"Asia", "NULL"}, {"Africa", "7"}, {"Oceania", "4"}, {"America", "NULL"},
+			},
+		},
+		{
+			name:    "wrong number of values",
+			rows:    [][]table.Expression{{constant(value.NewStringValue("Oceania"))}},
+			wantErr: fmt.Errorf("table 'regions' has 2 fields, but 1 values are specified"),
+		},
+		{
+			name: "fractional string in int field",
+			rows: [][]table.Expression{
+				{constant(value.NewStringValue("Oceania")), constant(value.NewStringValue("4.5"))},
+			},
+			wantErr: fmt.Errorf("invalid value of field 'units': strconv.ParseInt: parsing \"4.5\": invalid syntax"),
+		},
+		{
+			name: "null in not nullable field",
+			rows: [][]table.Expression{
+				{constant(value.NewNullValue()), constant(value.NewIntValueFromInt64(1))},
+			},
+			wantErr: fmt.Errorf("field 'region' cannot be null"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions := newRegions()
+			got, err := Insert(context.Background(), regions, tt.rows)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rows(got))
+			assert.Equal(t, 3, regions.RowCount())
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		rowIndexes  []int
+		assignments []table.Assignment
+		want        [][]string
+		wantErr     error
+	}{
+		{
+			name:       "expression uses values before update",
+			rowIndexes: []int{0, 2},
+			assignments: []table.Assignment{
+				{ColumnName: "units", Expression: expression.ArithmeticExpression{
+					Type:  table.ArithmeticOperationTypeMultiply,
+					Left:  expression.ColumnExpression{ColumnName: "units"},
+					Right: constant(value.NewIntValueFromInt64(2)),
+				}},
+				{ColumnName: "region", Expression: expression.ColumnExpression{ColumnName: "units"}},
+			},
+			want: [][]string{{"10", "20"}, {"Asia", "NULL"}, {"7", "14"}},
+		},
+		{
+			name:       "incompatible type",
+			rowIndexes: []int{0},
+			assignments: []table.Assignment{
+				{ColumnName: "units", Expression: constant(value.NewBoolValueFromBool(true))},
+			},
+			wantErr: fmt.Errorf("invalid value of field 'units': cannot cast bool to int"),
+		},
+		{
+			name:       "non-integral value in int field",
+			rowIndexes: []int{2},
+			assignments: []table.Assignment{
+				{ColumnName: "units", Expression: expression.ArithmeticExpression{
+					Type:  table.ArithmeticOperationTypeMultiply,
+					Left:  expression.ColumnExpression{ColumnName: "units"},
+					Right: constant(value.NewNumberValueFromFloat(2.5)),
+				}},
+			},
+			wantErr: fmt.Errorf("invalid value of field 'units': '17.5' is not an integer"),
+		},
+		{
+			name:       "unknown field",
+			rowIndexes: []int{0},
+			assignments: []table.Assignment{
+				{ColumnName: "manager", Expression: constant(value.NewStringValue("Anna"))},
+			},
+			wantErr: fmt.Errorf("field 'manager' not found in table 'regions'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions := newRegions()
+			got, err := Update(context.Background(), regions, tt.rowIndexes, tt.assignments)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rows(got))
+			assert.Equal(t, "10", regions.Columns[1].Values[0].String())
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	regions := newRegions()
+	got, err := Delete(context.Background(), regions, []int{0, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Asia", "NULL"}}, rows(got))
+	assert.Equal(t, 3, regions.RowCount())
+}
+
+func TestUpdateDecimalScale(t *testing.T) {
+	prices := table.NewTable("prices", []table.Column{
+		{
+			Field:  table.Field{Name: "price", Type: table.FieldTypeDecimal, Scale: 2},
+			Values: []table.Value{value.NewDecimalValueFromUnits(1050, 2)},
+		},
+	})
+	multiply := func(by string, scale int) []table.Assignment {
+		factor, err := value.NewDecimalValue(by, scale)
+		assert.NoError(t, err)
+
+		return []table.Assignment{{ColumnName: "price", Expression: expression.ArithmeticExpression{
+			Type:  table.ArithmeticOperationTypeMultiply,
+			Left:  expression.ColumnExpression{ColumnName: "price"},
+			Right: constant(factor),
+		}}}
+	}
+
+	got, err := Update(context.Background(), prices, []int{0}, multiply("2.000", 3))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"21.00"}}, rows(got))
+
+	_, err = Update(context.Background(), prices, []int{0}, multiply("1.05", 2))
+	assert.EqualError(t, err, "invalid value of field 'price': '11.0250' has more than 2 digits after the decimal point")
+}
@@ -0,0 +1,272 @@
+package expression
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+var _ table.Expression = CastExpression{}
+
+// fieldTypeNull - тип литерала null, совместимый с любым типом
+const fieldTypeNull table.FieldType = -1
+
+// defaultDecimalScale - число знаков после запятой при приведении к decimal
+const defaultDecimalScale = 2
+
+// NewCastExpression приводит значение operand к типу typeName
+func NewCastExpression(operand table.Expression, typeName string) (CastExpression, error) {
+	field := table.Field{}
+	switch typeName {
+	case "number":
+		field.Type = table.FieldTypeNumber
+	case "string":
+		field.Type = table.FieldTypeString
+	case "int":
+		field.Type = table.FieldTypeInt
+	case "decimal":
+		field.Type = table.FieldTypeDecimal
+		field.Scale = defaultDecimalScale
+	case "bool":
+		field.Type = table.FieldTypeBool
+	case "date":
+		field.Type = table.FieldTypeDate
+		field.Layout = value.DefaultDateLayout
+	case "datetime":
+		field.Type = table.FieldTypeDatetime
+		field.Layout = value.DefaultDatetimeLayout
+	default:
+		return CastExpression{}, fmt.Errorf("unknown type '%s'", typeName)
+	}
+
+	return CastExpression{Operand: operand, Target: field}, nil
+}
+
+// CastExpression приводит значение к типу Target. Строка приводится к любому типу,
+// любое значение - к строке, числа - к другим числовым типам, date и datetime - друг к другу.
+type CastExpression struct {
+	Operand table.Expression
+	Target  table.Field
+}
+
+func (e CastExpression) String() string {
+	return fmt.Sprintf("cast(%s as %s)", e.Operand, e.Target.Type)
+}
+
+func (e CastExpression) Field(t table.Table) (table.Field, error) {
+	fields, err := argFields(t, []table.Expression{e.Operand})
+	if err != nil {
+		return table.Field{}, err
+	}
+
+	from := fields[0]
+	ret := e.Target
+	ret.Nullable = from.Nullable
+	if from.Type == table.FieldTypeDecimal && ret.Type == table.FieldTypeDecimal {
+		ret.Scale = from.Scale
+	}
+
+	isTime := func(fieldType table.FieldType) bool {
+		return fieldType == table.FieldTypeDate || fieldType == table.FieldTypeDatetime
+	}
+	switch {
+	case from.Type == ret.Type, from.Type == fieldTypeNull:
+	case from.Type == table.FieldTypeString, ret.Type == table.FieldTypeString:
+	case from.Type.IsNumeric() && ret.Type.IsNumeric():
+	case isTime(from.Type) && isTime(ret.Type):
+	default:
+		return table.Field{}, fmt.Errorf("cannot cast %s to %s", from.Type, ret.Type)
+	}
+
+	return ret, nil
+}
+
+func (e CastExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	field, err := e.Field(t)
+	if err != nil {
+		return nil, err
+	}
+	val, err := e.Operand.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(val, field)
+}
+
+// ConvertExact приводит значение к типу поля field для записи в таблицу. В отличие от cast,
+// значение не округляется: дробное число в поле int и лишние знаки после запятой в поле decimal - ошибка.
+// Совместимость типов должна быть проверена заранее, например, через CastExpression.Field.
+func ConvertExact(val table.Value, field table.Field) (table.Value, error) {
+	ret, err := convert(val, field)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Value() == nil || (field.Type != table.FieldTypeInt && field.Type != table.FieldTypeDecimal) {
+		return ret, nil
+	}
+
+	src := val.Value()
+	if s, isString := src.(string); isString {
+		r, valid := new(big.Rat).SetString(strings.TrimSpace(s))
+		if !valid {
+			return ret, nil
+		}
+		src = r
+	}
+	from, valid := table.ToRat(src)
+	if !valid {
+		return ret, nil
+	}
+	if to, _ := table.ToRat(ret.Value()); from.Cmp(to) == 0 {
+		return ret, nil
+	}
+	if field.Type == table.FieldTypeInt {
+		return nil, fmt.Errorf("'%v' is not an integer", val)
+	}
+
+	return nil, fmt.Errorf("'%v' has more than %d digits after the decimal point", val, field.Scale)
+}
+
+// convert приводит значение к типу поля field
+func convert(val table.Value, field table.Field) (table.Value, error) {
+	if val.Value() == nil {
+		return value.NewNullValue(), nil
+	}
+	if s, isString := val.Value().(string); isString && field.Type != table.FieldTypeString {
+		return parse(strings.TrimSpace(s), field)
+	}
+
+	switch field.Type {
+	case table.FieldTypeString:
+		if _, isString := val.(value.StringValue); isString {
+			return val, nil
+		}
+
+		return value.NewStringValue(val.String()), nil
+	case table.FieldTypeNumber:
+		return value.NewNumberValueFromFloat(toFloat(val.Value())), nil
+	case table.FieldTypeInt:
+		if _, isInt := val.Value().(int64); isInt {
+			return val, nil
+		}
+		r, err := exactRat(val)
+		if err != nil {
+			return nil, err
+		}
+		rounded, err := value.NewDecimalValueFromRat(r, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return value.NewIntValueFromInt64(rounded.Units()), nil
+	case table.FieldTypeDecimal:
+		r, err := exactRat(val)
+		if err != nil {
+			return nil, err
+		}
+
+		return value.NewDecimalValueFromRat(r, field.Scale)
+	case table.FieldTypeDate, table.FieldTypeDatetime:
+		if t, isTime := val.Value().(time.Time); isTime {
+			return value.NewTimeValueFromTime(t, field.Layout), nil
+		}
+	case table.FieldTypeBool:
+		if _, isBool := val.Value().(bool); isBool {
+			return val, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert '%v' to %s", val, field.Type)
+}
+
+func parse(s string, field table.Field) (table.Value, error) {
+	switch field.Type {
+	case table.FieldTypeNumber:
+		return value.NewNumberValue(s)
+	case table.FieldTypeInt:
+		return value.NewIntValue(s)
+	case table.FieldTypeDecimal:
+		return value.NewDecimalValue(s, field.Scale)
+	case table.FieldTypeBool:
+		return value.NewBoolValue(s)
+	case table.FieldTypeDate, table.FieldTypeDatetime:
+		return value.NewTimeValue(s, field.Layout)
+	}
+
+	return nil, fmt.Errorf("cannot convert '%s' to %s", s, field.Type)
+}
+
+// exactRat приводит число к дроби без потери точности
+func exactRat(val table.Value) (*big.Rat, error) {
+	r, valid := table.ToRat(val.Value())
+	if !valid {
+		return nil, fmt.Errorf("cannot convert '%v' to exact number", val)
+	}
+
+	return r, nil
+}
+
+// argFields возвращает поля аргументов, литерал null получает тип, совместимый с любым другим
+func argFields(t table.Table, args []table.Expression) ([]table.Field, error) {
+	ret := make([]table.Field, 0, len(args))
+	for _, arg := range args {
+		field, err := arg.Field(t)
+		if err != nil {
+			return nil, err
+		}
+		if c, isConst := arg.(ConstExpression); isConst {
+			field = constField(c, field)
+		}
+		ret = append(ret, field)
+	}
+
+	return ret, nil
+}
+
+func constField(c ConstExpression, field table.Field) table.Field {
+	if c.Value.Value() == nil {
+		return table.Field{Type: fieldTypeNull, Nullable: true}
+	}
+
+	return field
+}
+
+// commonField возвращает тип, к которому приводятся значения всех полей: совпадающий тип
+// или, для разных числовых типов, number, если он есть среди них, иначе decimal
+func commonField(fields []table.Field) (table.Field, error) {
+	ret := table.Field{Type: fieldTypeNull}
+	for _, f := range fields {
+		switch {
+		case f.Type == fieldTypeNull:
+		case ret.Type == fieldTypeNull:
+			ret = table.Field{Type: f.Type, Layout: f.Layout, Scale: f.Scale}
+		case ret.Type == f.Type:
+			if f.Scale > ret.Scale {
+				ret.Scale = f.Scale
+			}
+		case ret.Type.IsNumeric() && f.Type.IsNumeric():
+			switch {
+			case ret.Type == table.FieldTypeNumber || f.Type == table.FieldTypeNumber:
+				ret = table.Field{Type: table.FieldTypeNumber}
+			default:
+				ret.Scale = decimalScale(ret)
+				if decimalScale(f) > ret.Scale {
+					ret.Scale = decimalScale(f)
+				}
+				ret.Type = table.FieldTypeDecimal
+			}
+		default:
+			return table.Field{}, fmt.Errorf("incompatible types %s and %s", ret.Type, f.Type)
+		}
+	}
+	if ret.Type == fieldTypeNull {
+		return table.Field{Type: table.FieldTypeString, Nullable: true}, nil
+	}
+
+	return ret, nil
+}
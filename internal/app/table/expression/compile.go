@@ -0,0 +1,127 @@
+package expression
+
+import (
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+// Compile проверяет типы выражения по полям таблицы t и возвращает выражение, в котором типы
+// результатов арифметики, функций и case определены заранее, а не для каждой строки. Выражение можно
+// вычислять для таблиц с теми же полями, что и t.
+func Compile(e table.Expression, t table.Table) (table.Expression, error) {
+	if _, err := e.Field(t); err != nil {
+		return nil, err
+	}
+
+	return compile(e, t)
+}
+
+func compile(e table.Expression, t table.Table) (table.Expression, error) {
+	var err error
+	switch v := e.(type) {
+	case NegateExpression:
+		e, err = compileNegate(v, t)
+	case NotExpression:
+		err = compileAll(t, &v.Operand)
+		e = v
+	case IsNullExpression:
+		err = compileAll(t, &v.Operand)
+		e = v
+	case CastExpression:
+		err = compileAll(t, &v.Operand)
+		e = v
+	case ArithmeticExpression:
+		e, err = compileArithmetic(v, t)
+	case CompareExpression:
+		err = compileAll(t, &v.Left, &v.Right)
+		e = v
+	case LogicalExpression:
+		err = compileAll(t, &v.Left, &v.Right)
+		e = v
+	case CaseExpression:
+		e, err = compileCase(v, t)
+	case FunctionExpression:
+		e, err = compileFunction(v, t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func compileNegate(e NegateExpression, t table.Table) (table.Expression, error) {
+	if err := compileAll(t, &e.Operand); err != nil {
+		return nil, err
+	}
+	field, err := e.Field(t)
+	if err != nil {
+		return nil, err
+	}
+	e.result = &field
+
+	return e, nil
+}
+
+func compileArithmetic(e ArithmeticExpression, t table.Table) (table.Expression, error) {
+	if err := compileAll(t, &e.Left, &e.Right); err != nil {
+		return nil, err
+	}
+	field, err := e.Field(t)
+	if err != nil {
+		return nil, err
+	}
+	e.result = &field
+
+	return e, nil
+}
+
+func compileCase(e CaseExpression, t table.Table) (table.Expression, error) {
+	// Ветки копируются, чтобы не изменить выражение разобранного запроса
+	e.Whens = append([]CaseWhen(nil), e.Whens...)
+	for i := range e.Whens {
+		if err := compileAll(t, &e.Whens[i].Condition, &e.Whens[i].Result); err != nil {
+			return nil, err
+		}
+	}
+	if err := compileAll(t, &e.Else); err != nil {
+		return nil, err
+	}
+	field, err := e.Field(t)
+	if err != nil {
+		return nil, err
+	}
+	e.result = &field
+
+	return e, nil
+}
+
+func compileFunction(e FunctionExpression, t table.Table) (table.Expression, error) {
+	e.Args = append([]table.Expression(nil), e.Args...)
+	for i := range e.Args {
+		if err := compileAll(t, &e.Args[i]); err != nil {
+			return nil, err
+		}
+	}
+	field, err := e.Field(t)
+	if err != nil {
+		return nil, err
+	}
+	e.result = &field
+
+	return e, nil
+}
+
+func compileAll(t table.Table, exprs ...*table.Expression) error {
+	for _, e := range exprs {
+		if *e == nil {
+			continue
+		}
+		compiled, err := compile(*e, t)
+		if err != nil {
+			return err
+		}
+		*e = compiled
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+var _ table.Expression = CompareExpression{}
+var _ table.Expression = LogicalExpression{}
+var _ table.Expression = NotExpression{}
+var _ table.Expression = IsNullExpression{}
+var _ table.Expression = CaseExpression{}
+
+// CompareExpression сравнивает значения двух выражений. Сравнение с null даёт null.
+type CompareExpression struct {
+	Type  table.CompareOperationType
+	Left  table.Expression
+	Right table.Expression
+}
+
+func (e CompareExpression) String() string {
+	return fmt.Sprintf("%s %s %s", wrap(e.Left), e.Type, wrap(e.Right))
+}
+
+func (e CompareExpression) Field(t table.Table) (table.Field, error) {
+	fields, err := argFields(t, []table.Expression{e.Left, e.Right})
+	if err != nil {
+		return table.Field{}, err
+	}
+	left, right := fields[0], fields[1]
+
+	switch e.Type {
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike, table.CompareOperationTypeRegexp:
+		for _, f := range fields {
+			if !argKindString.match(f.Type) {
+				return table.Field{}, fmt.Errorf("%s is only supported for string fields, got %s", e.Type, f.Type)
+			}
+		}
+	default:
+		// Строковый литерал сравнивается со значением другого типа по правилам этого типа: date_field > '2020-01-01'
+		_, err = commonField(fields)
+		if err != nil && !isStringConst(e.Left) && !isStringConst(e.Right) {
+			return table.Field{}, err
+		}
+	}
+
+	return table.Field{Type: table.FieldTypeBool, Nullable: left.Nullable || right.Nullable}, nil
+}
+
+func (e CompareExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	left, err := e.Left.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Right.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	if left.Value() == nil || right.Value() == nil {
+		return value.NewNullValue(), nil
+	}
+
+	res, err := compare(left, right, e.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.NewBoolValueFromBool(res), nil
+}
+
+func isStringConst(e table.Expression) bool {
+	c, isConst := e.(ConstExpression)
+	if !isConst {
+		return false
+	}
+	_, isString := c.Value.Value().(string)
+
+	return isString
+}
+
+func compare(left, right table.Value, op table.CompareOperationType) (bool, error) {
+	switch op {
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike, table.CompareOperationTypeRegexp:
+		return left.Compare(right.Value(), op)
+	}
+
+	res, err := table.CompareValues(left, right)
+	if err != nil {
+		if _, isString := right.Value().(string); isString {
+			return left.Compare(right.Value(), op)
+		}
+		if _, isString := left.Value().(string); isString {
+			return right.Compare(left.Value(), op.Mirror())
+		}
+
+		return false, err
+	}
+
+	switch op {
+	case table.CompareOperationTypeEqual:
+		return res == 0, nil
+	case table.CompareOperationTypeNotEqual:
+		return res != 0, nil
+	case table.CompareOperationTypeLess:
+		return res < 0, nil
+	case table.CompareOperationTypeLessOrEqual:
+		return res <= 0, nil
+	case table.CompareOperationTypeMore:
+		return res > 0, nil
+	case table.CompareOperationTypeMoreOrEqual:
+		return res >= 0, nil
+	}
+
+	return false, fmt.Errorf("unknown compare operation: %s", op)
+}
+
+// LogicalExpression вычисляет and и or по правилам трёхзначной логики
+type LogicalExpression struct {
+	Type  table.LogicalOperationType
+	Left  table.Expression
+	Right table.Expression
+}
+
+func (e LogicalExpression) String() string {
+	return fmt.Sprintf("%s %s %s", wrap(e.Left), e.Type, wrap(e.Right))
+}
+
+func (e LogicalExpression) Field(t table.Table) (table.Field, error) {
+	return boolField(t, e.Type, e.Left, e.Right)
+}
+
+func (e LogicalExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	left, err := e.Left.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Right.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат определён, если один из операндов его задаёт: false для and, true для or
+	decisive := e.Type == table.LogicalOperationTypeOr
+	for _, val := range []table.Value{left, right} {
+		if val.Value() == decisive {
+			return value.NewBoolValueFromBool(decisive), nil
+		}
+	}
+	if left.Value() == nil || right.Value() == nil {
+		return value.NewNullValue(), nil
+	}
+
+	return value.NewBoolValueFromBool(!decisive), nil
+}
+
+// NotExpression - отрицание, not null даёт null
+type NotExpression struct {
+	Operand table.Expression
+}
+
+func (e NotExpression) String() string {
+	return "not " + wrap(e.Operand)
+}
+
+func (e NotExpression) Field(t table.Table) (table.Field, error) {
+	return boolField(t, "not", e.Operand)
+}
+
+func (e NotExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	val, err := e.Operand.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	b, isBool := val.Value().(bool)
+	if !isBool {
+		return value.NewNullValue(), nil
+	}
+
+	return value.NewBoolValueFromBool(!b), nil
+}
+
+// IsNullExpression проверяет, что значение отсутствует, или, если Not, что оно указано
+type IsNullExpression struct {
+	Operand table.Expression
+	Not     bool
+}
+
+func (e IsNullExpression) String() string {
+	if e.Not {
+		return wrap(e.Operand) + " is not null"
+	}
+
+	return wrap(e.Operand) + " is null"
+}
+
+func (e IsNullExpression) Field(t table.Table) (table.Field, error) {
+	if _, err := e.Operand.Field(t); err != nil {
+		return table.Field{}, err
+	}
+
+	return table.Field{Type: table.FieldTypeBool}, nil
+}
+
+func (e IsNullExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	val, err := e.Operand.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.NewBoolValueFromBool((val.Value() == nil) != e.Not), nil
+}
+
+// CaseWhen - условие выражения case и значение, которое возвращается при его выполнении
+type CaseWhen struct {
+	Condition table.Expression
+	Result    table.Expression
+}
+
+// NewCaseExpression собирает выражение case из аргументов: пар условие-значение
+// и значения else в конце, если оно указано
+func NewCaseExpression(args []table.Expression) (CaseExpression, error) {
+	if len(args) < 2 {
+		return CaseExpression{}, fmt.Errorf("case should contain when and then")
+	}
+
+	var ret CaseExpression
+	for i := 0; i+1 < len(args); i += 2 {
+		ret.Whens = append(ret.Whens, CaseWhen{Condition: args[i], Result: args[i+1]})
+	}
+	if len(args)%2 == 1 {
+		ret.Else = args[len(args)-1]
+	}
+
+	return ret, nil
+}
+
+// CaseExpression возвращает значение первой ветки с выполненным условием, иначе значение Else или null
+type CaseExpression struct {
+	Whens []CaseWhen
+	Else  table.Expression
+	// result - тип результата, определённый при компиляции выражения
+	result *table.Field
+}
+
+func (e CaseExpression) String() string {
+	var sb strings.Builder
+	sb.WriteString("case")
+	for _, w := range e.Whens {
+		fmt.Fprintf(&sb, " when %s then %s", w.Condition, w.Result)
+	}
+	if e.Else != nil {
+		fmt.Fprintf(&sb, " else %s", e.Else)
+	}
+	sb.WriteString(" end")
+
+	return sb.String()
+}
+
+func (e CaseExpression) Field(t table.Table) (table.Field, error) {
+	results := make([]table.Expression, 0, len(e.Whens)+1)
+	for _, w := range e.Whens {
+		if _, err := boolField(t, "when", w.Condition); err != nil {
+			return table.Field{}, err
+		}
+		results = append(results, w.Result)
+	}
+	if e.Else != nil {
+		results = append(results, e.Else)
+	}
+
+	fields, err := argFields(t, results)
+	if err != nil {
+		return table.Field{}, err
+	}
+	ret, err := commonField(fields)
+	if err != nil {
+		return table.Field{}, fmt.Errorf("case: %w", err)
+	}
+	ret.Nullable = e.Else == nil
+	for _, f := range fields {
+		ret.Nullable = ret.Nullable || f.Nullable
+	}
+
+	return ret, nil
+}
+
+func (e CaseExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	field, err := e.resultField(t)
+	if err != nil {
+		return nil, err
+	}
+
+	result := e.Else
+	for _, w := range e.Whens {
+		cond, err := w.Condition.Evaluate(t, rowIndex)
+		if err != nil {
+			return nil, err
+		}
+		if cond.Value() == true {
+			result = w.Result
+
+			break
+		}
+	}
+	if result == nil {
+		return value.NewNullValue(), nil
+	}
+
+	val, err := result.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Значения разных веток приводятся к общему типу
+	return convert(val, field)
+}
+
+func (e CaseExpression) resultField(t table.Table) (table.Field, error) {
+	if e.result != nil {
+		return *e.result, nil
+	}
+
+	return e.Field(t)
+}
+
+// boolField проверяет, что операнды логической операции op - условия
+func boolField(t table.Table, op interface{}, operands ...table.Expression) (table.Field, error) {
+	fields, err := argFields(t, operands)
+	if err != nil {
+		return table.Field{}, err
+	}
+
+	ret := table.Field{Type: table.FieldTypeBool}
+	for _, f := range fields {
+		if f.Type != table.FieldTypeBool && f.Type != fieldTypeNull {
+			return table.Field{}, fmt.Errorf("operation %s is not applicable to %s", op, f.Type)
+		}
+		ret.Nullable = ret.Nullable || f.Nullable
+	}
+
+	return ret, nil
+}
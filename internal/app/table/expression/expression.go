@@ -0,0 +1,324 @@
+package expression
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+var _ table.Expression = ColumnExpression{}
+var _ table.Expression = ConstExpression{}
+var _ table.Expression = NegateExpression{}
+var _ table.Expression = ArithmeticExpression{}
+
+// ColumnExpression возвращает значение поля
+type ColumnExpression struct {
+	ColumnName string
+}
+
+func (e ColumnExpression) String() string {
+	return e.ColumnName
+}
+
+func (e ColumnExpression) Field(t table.Table) (table.Field, error) {
+	col, err := t.GetColumnByName(e.ColumnName)
+	if err != nil {
+		return table.Field{}, err
+	}
+	field := col.Field
+	field.Name = ""
+
+	return field, nil
+}
+
+func (e ColumnExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	col, err := t.GetColumnByName(e.ColumnName)
+	if err != nil {
+		return nil, err
+	}
+
+	return col.Values[rowIndex], nil
+}
+
+// ConstExpression возвращает значение, указанное в запросе
+type ConstExpression struct {
+	Value table.Value
+}
+
+func (e ConstExpression) String() string {
+	switch e.Value.(type) {
+	case value.StringValue:
+		return "'" + strings.ReplaceAll(e.Value.String(), "'", "\\'") + "'"
+	case value.NullValue:
+		return "null"
+	}
+
+	return e.Value.String()
+}
+
+func (e ConstExpression) Field(table.Table) (table.Field, error) {
+	switch v := e.Value.(type) {
+	case value.NumberValue:
+		return table.Field{Type: table.FieldTypeNumber}, nil
+	case value.IntValue:
+		return table.Field{Type: table.FieldTypeInt}, nil
+	case value.DecimalValue:
+		return table.Field{Type: table.FieldTypeDecimal, Scale: v.Scale()}, nil
+	case value.BoolValue:
+		return table.Field{Type: table.FieldTypeBool}, nil
+	case value.NullValue:
+		return table.Field{Type: table.FieldTypeString, Nullable: true}, nil
+	}
+
+	return table.Field{Type: table.FieldTypeString}, nil
+}
+
+func (e ConstExpression) Evaluate(table.Table, int) (table.Value, error) {
+	return e.Value, nil
+}
+
+// NegateExpression меняет знак числа
+type NegateExpression struct {
+	Operand table.Expression
+	// result - тип результата, определённый при компиляции выражения
+	result *table.Field
+}
+
+func (e NegateExpression) String() string {
+	return "-" + wrap(e.Operand)
+}
+
+func (e NegateExpression) Field(t table.Table) (table.Field, error) {
+	fields, err := argFields(t, []table.Expression{e.Operand})
+	if err != nil {
+		return table.Field{}, err
+	}
+	field := fields[0]
+	if field.Type == fieldTypeNull {
+		return table.Field{Type: table.FieldTypeNumber, Nullable: true}, nil
+	}
+	if !field.Type.IsNumeric() {
+		return table.Field{}, fmt.Errorf("operation - is not applicable to %s", field.Type)
+	}
+
+	return field, nil
+}
+
+func (e NegateExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	field, err := e.resultField(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Смена знака равносильна вычитанию из нуля того же типа, тип разности совпадает с типом операнда
+	return ArithmeticExpression{
+		Type:   table.ArithmeticOperationTypeMinus,
+		Left:   ConstExpression{Value: zero(field)},
+		Right:  e.Operand,
+		result: &field,
+	}.Evaluate(t, rowIndex)
+}
+
+func (e NegateExpression) resultField(t table.Table) (table.Field, error) {
+	if e.result != nil {
+		return *e.result, nil
+	}
+
+	return e.Field(t)
+}
+
+// ArithmeticExpression выполняет арифметическую операцию над числами. Сложение, вычитание и умножение
+// полей int и decimal выполняются точно, деление decimal округляется до большего из масштабов.
+type ArithmeticExpression struct {
+	Type  table.ArithmeticOperationType
+	Left  table.Expression
+	Right table.Expression
+	// result - тип результата, определённый при компиляции выражения
+	result *table.Field
+}
+
+func (e ArithmeticExpression) String() string {
+	return fmt.Sprintf("%s %s %s", wrap(e.Left), e.Type, wrap(e.Right))
+}
+
+func (e ArithmeticExpression) Field(t table.Table) (table.Field, error) {
+	fields, err := argFields(t, []table.Expression{e.Left, e.Right})
+	if err != nil {
+		return table.Field{}, err
+	}
+	// Литерал null принимает тип другого операнда, результат операции с ним - null
+	left, right := fields[0], fields[1]
+	switch {
+	case left.Type == fieldTypeNull && right.Type == fieldTypeNull:
+		return table.Field{Type: table.FieldTypeNumber, Nullable: true}, nil
+	case left.Type == fieldTypeNull:
+		left = table.Field{Type: right.Type, Nullable: true}
+	case right.Type == fieldTypeNull:
+		right = table.Field{Type: left.Type, Nullable: true}
+	}
+	for _, f := range []table.Field{left, right} {
+		if !f.Type.IsNumeric() {
+			return table.Field{}, fmt.Errorf("operation %s is not applicable to %s", e.Type, f.Type)
+		}
+	}
+
+	ret := table.Field{Nullable: left.Nullable || right.Nullable}
+	switch {
+	case left.Type == table.FieldTypeNumber || right.Type == table.FieldTypeNumber:
+		ret.Type = table.FieldTypeNumber
+	case left.Type == table.FieldTypeInt && right.Type == table.FieldTypeInt:
+		ret.Type = table.FieldTypeInt
+		if e.Type == table.ArithmeticOperationTypeDivide {
+			ret.Type = table.FieldTypeNumber
+		}
+	default:
+		ret.Type = table.FieldTypeDecimal
+		ret.Scale = decimalScale(left)
+		if scale := decimalScale(right); scale > ret.Scale {
+			ret.Scale = scale
+		}
+		if e.Type == table.ArithmeticOperationTypeMultiply {
+			ret.Scale = decimalScale(left) + decimalScale(right)
+		}
+		if ret.Scale > value.MaxDecimalScale {
+			ret.Scale = value.MaxDecimalScale
+		}
+	}
+
+	return ret, nil
+}
+
+func (e ArithmeticExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	field, err := e.resultField(t)
+	if err != nil {
+		return nil, err
+	}
+	left, err := e.Left.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Right.Evaluate(t, rowIndex)
+	if err != nil {
+		return nil, err
+	}
+	if left.Value() == nil || right.Value() == nil {
+		return value.NewNullValue(), nil
+	}
+
+	if field.Type == table.FieldTypeNumber {
+		return e.evaluateFloat(left.Value(), right.Value())
+	}
+	l, r := toRat(left.Value()), toRat(right.Value())
+	if e.Type == table.ArithmeticOperationTypeDivide && r.Sign() == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+
+	res := new(big.Rat)
+	switch e.Type {
+	case table.ArithmeticOperationTypePlus:
+		res.Add(l, r)
+	case table.ArithmeticOperationTypeMinus:
+		res.Sub(l, r)
+	case table.ArithmeticOperationTypeMultiply:
+		res.Mul(l, r)
+	case table.ArithmeticOperationTypeDivide:
+		res.Quo(l, r)
+	default:
+		return nil, fmt.Errorf("unknown arithmetic operation: %s", e.Type)
+	}
+
+	if field.Type == table.FieldTypeInt {
+		if !res.Num().IsInt64() {
+			return nil, fmt.Errorf("result of '%s' is out of int range", e)
+		}
+
+		return value.NewIntValueFromInt64(res.Num().Int64()), nil
+	}
+
+	return value.NewDecimalValueFromRat(res, field.Scale)
+}
+
+func (e ArithmeticExpression) resultField(t table.Table) (table.Field, error) {
+	if e.result != nil {
+		return *e.result, nil
+	}
+
+	return e.Field(t)
+}
+
+func (e ArithmeticExpression) evaluateFloat(left, right interface{}) (table.Value, error) {
+	l, r := toFloat(left), toFloat(right)
+
+	switch e.Type {
+	case table.ArithmeticOperationTypePlus:
+		return value.NewNumberValueFromFloat(l + r), nil
+	case table.ArithmeticOperationTypeMinus:
+		return value.NewNumberValueFromFloat(l - r), nil
+	case table.ArithmeticOperationTypeMultiply:
+		return value.NewNumberValueFromFloat(l * r), nil
+	case table.ArithmeticOperationTypeDivide:
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+
+		return value.NewNumberValueFromFloat(l / r), nil
+	}
+
+	return nil, fmt.Errorf("unknown arithmetic operation: %s", e.Type)
+}
+
+// wrap заключает составное выражение в скобки, чтобы сохранить порядок вычисления в наименовании поля
+func wrap(e table.Expression) string {
+	switch e.(type) {
+	case ArithmeticExpression, NegateExpression, CompareExpression, LogicalExpression, NotExpression, IsNullExpression:
+		return "(" + e.String() + ")"
+	}
+
+	return e.String()
+}
+
+func decimalScale(field table.Field) int {
+	if field.Type == table.FieldTypeDecimal {
+		return field.Scale
+	}
+
+	return 0
+}
+
+func zero(field table.Field) table.Value {
+	switch field.Type {
+	case table.FieldTypeInt:
+		return value.NewIntValueFromInt64(0)
+	case table.FieldTypeDecimal:
+		return value.NewDecimalValueFromUnits(0, field.Scale)
+	}
+
+	return value.NewNumberValueFromFloat(0)
+}
+
+// toRat приводит числовое значение к дроби, для остальных значений возвращает 0
+func toRat(val interface{}) *big.Rat {
+	if r, valid := table.ToRat(val); valid {
+		return r
+	}
+
+	return new(big.Rat)
+}
+
+func toFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case *big.Rat:
+		f, _ := v.Float64()
+
+		return f
+	}
+
+	return 0
+}
@@ -0,0 +1,420 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTable() table.Table {
+	price1, _ := value.NewDecimalValue("10.25", 2)
+	price2, _ := value.NewDecimalValue("3.50", 2)
+	rate1, _ := value.NewNumberValue("0.5")
+	rate2, _ := value.NewNumberValue("2")
+
+	return table.NewTable("items", []table.Column{
+		{
+			Field:  table.Field{Name: "name", Type: table.FieldTypeString},
+			Values: []table.Value{value.NewStringValue("Pen"), value.NewStringValue("Cup")},
+		},
+		{
+			Field:  table.Field{Name: "qty", Type: table.FieldTypeInt, Nullable: true},
+			Values: []table.Value{value.NewIntValueFromInt64(3), value.NewNullValue()},
+		},
+		{
+			Field:  table.Field{Name: "price", Type: table.FieldTypeDecimal, Scale: 2},
+			Values: []table.Value{price1, price2},
+		},
+		{
+			Field:  table.Field{Name: "rate", Type: table.FieldTypeNumber},
+			Values: []table.Value{rate1, rate2},
+		},
+		{
+			Field:  table.Field{Name: "zero", Type: table.FieldTypeInt},
+			Values: []table.Value{value.NewIntValueFromInt64(0), value.NewIntValueFromInt64(0)},
+		},
+	})
+}
+
+func TestArithmeticExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      table.Expression
+		wantField table.Field
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "int plus int",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypePlus,
+				Left:  ColumnExpression{ColumnName: "qty"},
+				Right: ConstExpression{Value: value.NewIntValueFromInt64(1)},
+			},
+			wantField: table.Field{Type: table.FieldTypeInt, Nullable: true},
+			want:      []string{"4", "NULL"},
+		},
+		{
+			name: "decimal multiply int",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypeMultiply,
+				Left:  ColumnExpression{ColumnName: "price"},
+				Right: ColumnExpression{ColumnName: "qty"},
+			},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2, Nullable: true},
+			want:      []string{"30.75", "NULL"},
+		},
+		{
+			name: "decimal with number",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypeMultiply,
+				Left:  ColumnExpression{ColumnName: "price"},
+				Right: ColumnExpression{ColumnName: "rate"},
+			},
+			wantField: table.Field{Type: table.FieldTypeNumber},
+			want:      []string{"5.125", "7"},
+		},
+		{
+			name: "negate",
+			expr: NegateExpression{
+				Operand: ColumnExpression{ColumnName: "price"},
+			},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2},
+			want:      []string{"-10.25", "-3.50"},
+		},
+		{
+			name: "int plus null",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypePlus,
+				Left:  ColumnExpression{ColumnName: "zero"},
+				Right: ConstExpression{Value: value.NewNullValue()},
+			},
+			wantField: table.Field{Type: table.FieldTypeInt, Nullable: true},
+			want:      []string{"NULL", "NULL"},
+		},
+		{
+			name: "null multiply decimal",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypeMultiply,
+				Left:  ConstExpression{Value: value.NewNullValue()},
+				Right: ColumnExpression{ColumnName: "price"},
+			},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2, Nullable: true},
+			want:      []string{"NULL", "NULL"},
+		},
+		{
+			name:      "negate null",
+			expr:      NegateExpression{Operand: ConstExpression{Value: value.NewNullValue()}},
+			wantField: table.Field{Type: table.FieldTypeNumber, Nullable: true},
+			want:      []string{"NULL", "NULL"},
+		},
+		{
+			name: "string plus null",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypePlus,
+				Left:  ColumnExpression{ColumnName: "name"},
+				Right: ConstExpression{Value: value.NewNullValue()},
+			},
+			wantErr: true,
+		},
+		{
+			name: "upper",
+			expr: FunctionExpression{
+				Name: "upper",
+				Args: []table.Expression{ColumnExpression{ColumnName: "name"}},
+			},
+			wantField: table.Field{Type: table.FieldTypeString},
+			want:      []string{"PEN", "CUP"},
+		},
+		{
+			name: "string operand",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypePlus,
+				Left:  ColumnExpression{ColumnName: "name"},
+				Right: ConstExpression{Value: value.NewIntValueFromInt64(1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "division by zero",
+			expr: ArithmeticExpression{
+				Type:  table.ArithmeticOperationTypeDivide,
+				Left:  ColumnExpression{ColumnName: "price"},
+				Right: ColumnExpression{ColumnName: "zero"},
+			},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2},
+			wantErr:   true,
+		},
+	}
+
+	tbl := newTestTable()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := tt.expr.Field(tbl)
+			if tt.wantErr && err != nil {
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantField, field)
+
+			got := make([]string, 0, tbl.RowCount())
+			for _, rowIndex := range tbl.RowIndexes() {
+				val, err := tt.expr.Evaluate(tbl, rowIndex)
+				if tt.wantErr {
+					assert.Error(t, err)
+
+					return
+				}
+				assert.NoError(t, err)
+				got = append(got, val.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestArithmeticExpression_String(t *testing.T) {
+	expr := ArithmeticExpression{
+		Type: table.ArithmeticOperationTypeMultiply,
+		Left: ArithmeticExpression{
+			Type:  table.ArithmeticOperationTypeMinus,
+			Left:  ColumnExpression{ColumnName: "total_revenue"},
+			Right: ColumnExpression{ColumnName: "total_cost"},
+		},
+		Right: ConstExpression{Value: value.NewIntValueFromInt64(2)},
+	}
+
+	assert.Equal(t, "(total_revenue - total_cost) * 2", expr.String())
+}
+
+func TestFunctionExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        string
+		args      []table.Expression
+		wantField table.Field
+		want      []string
+	}{
+		{
+			name:      "substr",
+			fn:        "substr",
+			args:      []table.Expression{ColumnExpression{ColumnName: "name"}, ConstExpression{Value: value.NewIntValueFromInt64(2)}},
+			wantField: table.Field{Type: table.FieldTypeString},
+			want:      []string{"en", "up"},
+		},
+		{
+			name:      "length",
+			fn:        "length",
+			args:      []table.Expression{ColumnExpression{ColumnName: "name"}},
+			wantField: table.Field{Type: table.FieldTypeInt},
+			want:      []string{"3", "3"},
+		},
+		{
+			name:      "concat skips null",
+			fn:        "concat",
+			args:      []table.Expression{ColumnExpression{ColumnName: "name"}, ColumnExpression{ColumnName: "qty"}},
+			wantField: table.Field{Type: table.FieldTypeString},
+			want:      []string{"Pen3", "Cup"},
+		},
+		{
+			name:      "round decimal",
+			fn:        "round",
+			args:      []table.Expression{ColumnExpression{ColumnName: "price"}, ConstExpression{Value: value.NewIntValueFromInt64(1)}},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2},
+			want:      []string{"10.30", "3.50"},
+		},
+		{
+			name:      "ceil decimal",
+			fn:        "ceil",
+			args:      []table.Expression{ColumnExpression{ColumnName: "price"}},
+			wantField: table.Field{Type: table.FieldTypeInt},
+			want:      []string{"11", "4"},
+		},
+		{
+			name:      "coalesce with common type",
+			fn:        "coalesce",
+			args:      []table.Expression{ColumnExpression{ColumnName: "qty"}, ColumnExpression{ColumnName: "price"}},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2},
+			want:      []string{"3.00", "3.50"},
+		},
+		{
+			name:      "nullif",
+			fn:        "nullif",
+			args:      []table.Expression{ColumnExpression{ColumnName: "name"}, ConstExpression{Value: value.NewStringValue("Pen")}},
+			wantField: table.Field{Type: table.FieldTypeString, Nullable: true},
+			want:      []string{"NULL", "Cup"},
+		},
+	}
+
+	tbl := newTestTable()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := NewFunctionExpression(tt.fn, tt.args)
+			assert.NoError(t, err)
+			field, err := expr.Field(tbl)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantField, field)
+
+			got := make([]string, 0, tbl.RowCount())
+			for _, rowIndex := range tbl.RowIndexes() {
+				val, err := expr.Evaluate(tbl, rowIndex)
+				assert.NoError(t, err)
+				got = append(got, val.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewFunctionExpression_Validation(t *testing.T) {
+	_, err := NewFunctionExpression("upper", []table.Expression{ConstExpression{Value: value.NewIntValueFromInt64(1)}})
+	assert.EqualError(t, err, "function upper: argument 1 should be string, got int")
+
+	_, err = NewFunctionExpression("replace", []table.Expression{ColumnExpression{ColumnName: "name"}})
+	assert.EqualError(t, err, "function replace: expected at least 3 arguments, got 1")
+
+	_, err = NewFunctionExpression("abs", []table.Expression{ConstExpression{Value: value.NewNullValue()}})
+	assert.NoError(t, err)
+
+	_, err = NewFunctionExpression("unknown", nil)
+	assert.EqualError(t, err, "unknown function: unknown")
+}
+
+func TestCompile(t *testing.T) {
+	tbl := newTestTable()
+	coalesce, err := NewFunctionExpression("coalesce", []table.Expression{
+		ColumnExpression{ColumnName: "qty"},
+		ColumnExpression{ColumnName: "price"},
+	})
+	assert.NoError(t, err)
+	expr := ArithmeticExpression{
+		Type:  table.ArithmeticOperationTypePlus,
+		Left:  coalesce,
+		Right: ConstExpression{Value: value.NewIntValueFromInt64(1)},
+	}
+
+	compiled, err := Compile(expr, tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, &table.Field{Type: table.FieldTypeDecimal, Scale: 2}, compiled.(ArithmeticExpression).Left.(FunctionExpression).result)
+	// Исходное выражение не изменяется
+	assert.Nil(t, expr.Left.(FunctionExpression).result)
+
+	got := make([]string, 0, tbl.RowCount())
+	for _, rowIndex := range tbl.RowIndexes() {
+		val, err := compiled.Evaluate(tbl, rowIndex)
+		assert.NoError(t, err)
+		got = append(got, val.String())
+	}
+	assert.Equal(t, []string{"4.00", "4.50"}, got)
+
+	compiled, err = Compile(NegateExpression{Operand: expr}, tbl)
+	assert.NoError(t, err)
+	decimal := &table.Field{Type: table.FieldTypeDecimal, Scale: 2}
+	assert.Equal(t, decimal, compiled.(NegateExpression).result)
+	assert.Equal(t, decimal, compiled.(NegateExpression).Operand.(ArithmeticExpression).result)
+	assert.Nil(t, expr.result)
+	val, err := compiled.Evaluate(tbl, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "-4.50", val.String())
+
+	upper, err := NewFunctionExpression("upper", []table.Expression{ColumnExpression{ColumnName: "qty"}})
+	assert.NoError(t, err)
+	_, err = Compile(NotExpression{Operand: IsNullExpression{Operand: upper}}, tbl)
+	assert.EqualError(t, err, "function upper: argument 1 should be string, got int")
+}
+
+func TestCaseAndCastExpression(t *testing.T) {
+	caseExpr, err := NewCaseExpression([]table.Expression{
+		IsNullExpression{Operand: ColumnExpression{ColumnName: "qty"}},
+		ConstExpression{Value: value.NewIntValueFromInt64(0)},
+		CompareExpression{
+			Type:  table.CompareOperationTypeMore,
+			Left:  ColumnExpression{ColumnName: "price"},
+			Right: ConstExpression{Value: value.NewIntValueFromInt64(5)},
+		},
+		ColumnExpression{ColumnName: "price"},
+	})
+	assert.NoError(t, err)
+
+	castExpr, err := NewCastExpression(ColumnExpression{ColumnName: "price"}, "int")
+	assert.NoError(t, err)
+
+	tbl := newTestTable()
+	for _, tt := range []struct {
+		expr      table.Expression
+		wantField table.Field
+		want      []string
+	}{
+		{
+			expr:      caseExpr,
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2, Nullable: true},
+			want:      []string{"10.25", "0.00"},
+		},
+		{
+			expr:      castExpr,
+			wantField: table.Field{Type: table.FieldTypeInt},
+			want:      []string{"10", "4"},
+		},
+	} {
+		field, err := tt.expr.Field(tbl)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.wantField, field)
+
+		got := make([]string, 0, tbl.RowCount())
+		for _, rowIndex := range tbl.RowIndexes() {
+			val, err := tt.expr.Evaluate(tbl, rowIndex)
+			assert.NoError(t, err)
+			got = append(got, val.String())
+		}
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err = CastExpression{Operand: ColumnExpression{ColumnName: "rate"}, Target: table.Field{Type: table.FieldTypeBool}}.Field(tbl)
+	assert.EqualError(t, err, "cannot cast number to bool")
+}
+
+func TestNumberAndDecimalColumns(t *testing.T) {
+	n, _ := value.NewNumberValue("0.1")
+	d, _ := value.NewDecimalValue("0.10", 2)
+	tbl := table.NewTable("amounts", []table.Column{
+		{Field: table.Field{Name: "n", Type: table.FieldTypeNumber}, Values: []table.Value{n}},
+		{Field: table.Field{Name: "d", Type: table.FieldTypeDecimal, Scale: 2}, Values: []table.Value{d}},
+	})
+
+	equal, err := CompareExpression{
+		Type:  table.CompareOperationTypeEqual,
+		Left:  ColumnExpression{ColumnName: "n"},
+		Right: ColumnExpression{ColumnName: "d"},
+	}.Evaluate(tbl, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, true, equal.Value())
+
+	diff, err := ArithmeticExpression{
+		Type:  table.ArithmeticOperationTypeMinus,
+		Left:  ColumnExpression{ColumnName: "n"},
+		Right: ColumnExpression{ColumnName: "d"},
+	}.Evaluate(tbl, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0", diff.String())
+
+	res, err := table.CompareValues(n, d)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, res)
+
+	nKey, _ := table.HashKey(n)
+	dKey, _ := table.HashKey(d)
+	assert.Equal(t, nKey, dKey)
+
+	nRow, valid := table.RowKey(tbl.Columns[:1], 0)
+	assert.True(t, valid)
+	dRow, _ := table.RowKey(tbl.Columns[1:], 0)
+	assert.Equal(t, nRow, dRow)
+
+	nullRow, valid := table.RowKey([]table.Column{{Values: []table.Value{value.NewNullValue()}}}, 0)
+	assert.False(t, valid)
+	assert.NotEqual(t, nRow, nullRow)
+}
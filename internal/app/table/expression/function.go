@@ -0,0 +1,560 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+var _ table.Expression = FunctionExpression{}
+
+// argKind - допустимые типы аргумента функции
+type argKind int
+
+const (
+	argKindAny     argKind = iota
+	argKindString  argKind = iota
+	argKindNumeric argKind = iota
+)
+
+func (k argKind) match(fieldType table.FieldType) bool {
+	switch k {
+	case argKindString:
+		return fieldType == table.FieldTypeString || fieldType == fieldTypeNull
+	case argKindNumeric:
+		return fieldType.IsNumeric() || fieldType == fieldTypeNull
+	}
+
+	return true
+}
+
+func (k argKind) String() string {
+	switch k {
+	case argKindString:
+		return "string"
+	case argKindNumeric:
+		return "number"
+	}
+
+	return "any"
+}
+
+// function описывает скалярную функцию: args - типы аргументов, для функций с переменным числом
+// аргументов последний тип повторяется; result возвращает тип результата по типам аргументов,
+// call вычисляет значение по значениям аргументов
+type function struct {
+	args     []argKind
+	minArgs  int
+	variadic bool
+	result   func(args []table.Field) (table.Field, error)
+	call     func(args []table.Value) (table.Value, error)
+	// nullSafe - функция сама обрабатывает null, иначе null в любом аргументе даёт null
+	nullSafe bool
+}
+
+func lookupFunction(name string) (function, bool) {
+	switch name {
+	case "upper":
+		return stringFunction(strings.ToUpper), true
+	case "lower":
+		return stringFunction(strings.ToLower), true
+	case "trim":
+		return stringFunction(strings.TrimSpace), true
+	case "length":
+		return lengthFunction(), true
+	case "substr":
+		return substrFunction(), true
+	case "concat":
+		return concatFunction(), true
+	case "replace":
+		return replaceFunction(), true
+	case "abs":
+		return absFunction(), true
+	case "round":
+		return roundFunction(), true
+	case "floor":
+		return floorFunction(false), true
+	case "ceil":
+		return floorFunction(true), true
+	case "coalesce":
+		return coalesceFunction(), true
+	case "nullif":
+		return nullifFunction(), true
+	}
+
+	return function{}, false
+}
+
+// NewFunctionExpression проверяет, что функция name существует, число аргументов допустимо,
+// а типы аргументов, известные без таблицы, подходят функции
+func NewFunctionExpression(name string, args []table.Expression) (FunctionExpression, error) {
+	f, found := lookupFunction(name)
+	if !found {
+		return FunctionExpression{}, fmt.Errorf("unknown function: %s", name)
+	}
+	if err := f.checkCount(len(args)); err != nil {
+		return FunctionExpression{}, fmt.Errorf("function %s: %w", name, err)
+	}
+	for i, arg := range args {
+		if c, isConst := arg.(ConstExpression); isConst {
+			field, _ := c.Field(table.Table{})
+			if err := f.checkArg(i, constField(c, field)); err != nil {
+				return FunctionExpression{}, fmt.Errorf("function %s: %w", name, err)
+			}
+		}
+	}
+
+	return FunctionExpression{Name: name, Args: args}, nil
+}
+
+func (f function) checkCount(count int) error {
+	maxArgs := len(f.args)
+	switch {
+	case count < f.minArgs:
+		return fmt.Errorf("expected at least %d arguments, got %d", f.minArgs, count)
+	case !f.variadic && count > maxArgs:
+		return fmt.Errorf("expected at most %d arguments, got %d", maxArgs, count)
+	}
+
+	return nil
+}
+
+func (f function) checkArg(i int, field table.Field) error {
+	kind := f.args[len(f.args)-1]
+	if i < len(f.args) {
+		kind = f.args[i]
+	}
+	if !kind.match(field.Type) {
+		return fmt.Errorf("argument %d should be %s, got %s", i+1, kind, field.Type)
+	}
+
+	return nil
+}
+
+// FunctionExpression вызывает скалярную функцию
+type FunctionExpression struct {
+	Name string
+	Args []table.Expression
+	// result - тип результата, определённый при компиляции выражения
+	result *table.Field
+}
+
+func (e FunctionExpression) String() string {
+	args := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		args = append(args, arg.String())
+	}
+
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+func (e FunctionExpression) Field(t table.Table) (table.Field, error) {
+	f, found := lookupFunction(e.Name)
+	if !found {
+		return table.Field{}, fmt.Errorf("unknown function: %s", e.Name)
+	}
+	if err := f.checkCount(len(e.Args)); err != nil {
+		return table.Field{}, fmt.Errorf("function %s: %w", e.Name, err)
+	}
+
+	args, err := argFields(t, e.Args)
+	if err != nil {
+		return table.Field{}, err
+	}
+	nullable := false
+	for i, arg := range args {
+		if err = f.checkArg(i, arg); err != nil {
+			return table.Field{}, fmt.Errorf("function %s: %w", e.Name, err)
+		}
+		nullable = nullable || arg.Nullable
+	}
+
+	field, err := f.result(args)
+	if err != nil {
+		return table.Field{}, fmt.Errorf("function %s: %w", e.Name, err)
+	}
+	if !f.nullSafe {
+		field.Nullable = field.Nullable || nullable
+	}
+
+	return field, nil
+}
+
+func (e FunctionExpression) Evaluate(t table.Table, rowIndex int) (table.Value, error) {
+	f, found := lookupFunction(e.Name)
+	if !found {
+		return nil, fmt.Errorf("unknown function: %s", e.Name)
+	}
+
+	args := make([]table.Value, 0, len(e.Args))
+	for _, arg := range e.Args {
+		val, err := arg.Evaluate(t, rowIndex)
+		if err != nil {
+			return nil, err
+		}
+		if val.Value() == nil && !f.nullSafe {
+			return value.NewNullValue(), nil
+		}
+		args = append(args, val)
+	}
+
+	if f.nullSafe {
+		field, err := e.resultField(t)
+		if err != nil {
+			return nil, err
+		}
+		ret, err := f.call(args)
+		if err != nil {
+			return nil, fmt.Errorf("function %s: %w", e.Name, err)
+		}
+
+		// Значения разных аргументов приводятся к общему типу результата
+		return convert(ret, field)
+	}
+
+	ret, err := f.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("function %s: %w", e.Name, err)
+	}
+
+	return ret, nil
+}
+
+func (e FunctionExpression) resultField(t table.Table) (table.Field, error) {
+	if e.result != nil {
+		return *e.result, nil
+	}
+
+	return e.Field(t)
+}
+
+// stringFunction - функция одного строкового аргумента, возвращающая строку
+func stringFunction(fn func(string) string) function {
+	return function{
+		args:    []argKind{argKindString},
+		minArgs: 1,
+		result:  resultOf(table.FieldTypeString),
+		call: func(args []table.Value) (table.Value, error) {
+			return value.NewStringValue(fn(args[0].String())), nil
+		},
+	}
+}
+
+func lengthFunction() function {
+	return function{
+		args:    []argKind{argKindString},
+		minArgs: 1,
+		result:  resultOf(table.FieldTypeInt),
+		call: func(args []table.Value) (table.Value, error) {
+			return value.NewIntValueFromInt64(int64(utf8.RuneCountInString(args[0].String()))), nil
+		},
+	}
+}
+
+// substrFunction возвращает часть строки с позиции start (начиная с 1) длиной length
+func substrFunction() function {
+	return function{
+		args:    []argKind{argKindString, argKindNumeric, argKindNumeric},
+		minArgs: 2,
+		result:  resultOf(table.FieldTypeString),
+		call: func(args []table.Value) (table.Value, error) {
+			runes := []rune(args[0].String())
+			start, err := toInt(args[1])
+			if err != nil {
+				return nil, err
+			}
+			begin, end := start-1, int64(len(runes))
+			if len(args) == 3 {
+				length, err := toInt(args[2])
+				if err != nil {
+					return nil, err
+				}
+				if length < 0 {
+					return nil, fmt.Errorf("negative substring length")
+				}
+				end = begin + length
+			}
+
+			begin, end = clamp(begin, int64(len(runes))), clamp(end, int64(len(runes)))
+			if begin >= end {
+				return value.NewStringValue(""), nil
+			}
+
+			return value.NewStringValue(string(runes[begin:end])), nil
+		},
+	}
+}
+
+// concatFunction соединяет строковые представления аргументов, пропуская null
+func concatFunction() function {
+	return function{
+		args:     []argKind{argKindAny},
+		minArgs:  1,
+		variadic: true,
+		nullSafe: true,
+		result:   resultOf(table.FieldTypeString),
+		call: func(args []table.Value) (table.Value, error) {
+			var sb strings.Builder
+			for _, arg := range args {
+				if arg.Value() != nil {
+					sb.WriteString(arg.String())
+				}
+			}
+
+			return value.NewStringValue(sb.String()), nil
+		},
+	}
+}
+
+func replaceFunction() function {
+	return function{
+		args:    []argKind{argKindString, argKindString, argKindString},
+		minArgs: 3,
+		result:  resultOf(table.FieldTypeString),
+		call: func(args []table.Value) (table.Value, error) {
+			return value.NewStringValue(strings.ReplaceAll(args[0].String(), args[1].String(), args[2].String())), nil
+		},
+	}
+}
+
+func absFunction() function {
+	return function{
+		args:    []argKind{argKindNumeric},
+		minArgs: 1,
+		result:  sameAsFirst,
+		call: func(args []table.Value) (table.Value, error) {
+			switch v := args[0].Value().(type) {
+			case float64:
+				return value.NewNumberValueFromFloat(math.Abs(v)), nil
+			case int64:
+				if v == math.MinInt64 {
+					return nil, fmt.Errorf("result is out of int range")
+				}
+				if v < 0 {
+					v = -v
+				}
+
+				return value.NewIntValueFromInt64(v), nil
+			case *big.Rat:
+				return value.NewDecimalValueFromRat(new(big.Rat).Abs(v), args[0].(value.DecimalValue).Scale())
+			}
+
+			return nil, fmt.Errorf("invalid number: '%v'", args[0])
+		},
+	}
+}
+
+// roundFunction округляет число до digits знаков после запятой, половина округляется от нуля.
+// Тип результата совпадает с типом числа.
+func roundFunction() function {
+	return function{
+		args:    []argKind{argKindNumeric, argKindNumeric},
+		minArgs: 1,
+		result:  sameAsFirst,
+		call: func(args []table.Value) (table.Value, error) {
+			var digits int64
+			if len(args) == 2 {
+				var err error
+				if digits, err = toInt(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			if digits > value.MaxDecimalScale || digits < -value.MaxDecimalScale {
+				return nil, fmt.Errorf("digits should be from -%d to %d", value.MaxDecimalScale, value.MaxDecimalScale)
+			}
+
+			if v, isFloat := args[0].Value().(float64); isFloat {
+				p := math.Pow(10, float64(digits))
+
+				return value.NewNumberValueFromFloat(math.Round(v*p) / p), nil
+			}
+
+			p := new(big.Rat).SetInt64(value.Pow10(int(abs(digits))))
+			r := toRat(args[0].Value())
+			if digits < 0 {
+				r = new(big.Rat).Quo(r, p)
+			} else {
+				r = new(big.Rat).Mul(r, p)
+			}
+			rounded, err := value.NewDecimalValueFromRat(r, 0)
+			if err != nil {
+				return nil, err
+			}
+			r = new(big.Rat).SetInt64(rounded.Units())
+			if digits < 0 {
+				r.Mul(r, p)
+			} else {
+				r.Quo(r, p)
+			}
+
+			return fromRat(r, args[0])
+		},
+	}
+}
+
+// floorFunction округляет число вниз или, если ceil, вверх до целого. Результат для int и decimal - int.
+func floorFunction(ceil bool) function {
+	return function{
+		args:    []argKind{argKindNumeric},
+		minArgs: 1,
+		result: func(args []table.Field) (table.Field, error) {
+			if args[0].Type == table.FieldTypeNumber {
+				return table.Field{Type: table.FieldTypeNumber}, nil
+			}
+
+			return table.Field{Type: table.FieldTypeInt}, nil
+		},
+		call: func(args []table.Value) (table.Value, error) {
+			if v, isFloat := args[0].Value().(float64); isFloat {
+				if ceil {
+					return value.NewNumberValueFromFloat(math.Ceil(v)), nil
+				}
+
+				return value.NewNumberValueFromFloat(math.Floor(v)), nil
+			}
+
+			r := toRat(args[0].Value())
+			if ceil {
+				r = new(big.Rat).Neg(r)
+			}
+			// Деление с остатком по Евклиду при положительном делителе округляет вниз
+			res := new(big.Int).Div(r.Num(), r.Denom())
+			if ceil {
+				res.Neg(res)
+			}
+			if !res.IsInt64() {
+				return nil, fmt.Errorf("result is out of int range")
+			}
+
+			return value.NewIntValueFromInt64(res.Int64()), nil
+		},
+	}
+}
+
+// coalesceFunction возвращает первый аргумент, отличный от null
+func coalesceFunction() function {
+	return function{
+		args:     []argKind{argKindAny},
+		minArgs:  1,
+		variadic: true,
+		nullSafe: true,
+		result: func(args []table.Field) (table.Field, error) {
+			field, err := commonField(args)
+			if err != nil {
+				return table.Field{}, err
+			}
+			field.Nullable = true
+			for _, arg := range args {
+				field.Nullable = field.Nullable && arg.Nullable
+			}
+
+			return field, nil
+		},
+		call: func(args []table.Value) (table.Value, error) {
+			for _, arg := range args {
+				if arg.Value() != nil {
+					return arg, nil
+				}
+			}
+
+			return value.NewNullValue(), nil
+		},
+	}
+}
+
+// nullifFunction возвращает null, если аргументы равны, иначе первый аргумент
+func nullifFunction() function {
+	return function{
+		args:     []argKind{argKindAny, argKindAny},
+		minArgs:  2,
+		nullSafe: true,
+		result: func(args []table.Field) (table.Field, error) {
+			if _, err := commonField(args); err != nil {
+				return table.Field{}, err
+			}
+			field := args[0]
+			field.Name = ""
+			field.Nullable = true
+
+			return field, nil
+		},
+		call: func(args []table.Value) (table.Value, error) {
+			if args[0].Value() == nil || args[1].Value() == nil {
+				return args[0], nil
+			}
+			res, err := table.CompareValues(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			if res == 0 {
+				return value.NewNullValue(), nil
+			}
+
+			return args[0], nil
+		},
+	}
+}
+
+func resultOf(fieldType table.FieldType) func([]table.Field) (table.Field, error) {
+	return func([]table.Field) (table.Field, error) {
+		return table.Field{Type: fieldType}, nil
+	}
+}
+
+func sameAsFirst(args []table.Field) (table.Field, error) {
+	return table.Field{Type: args[0].Type, Scale: args[0].Scale}, nil
+}
+
+// fromRat возвращает значение того же типа, что и like
+func fromRat(r *big.Rat, like table.Value) (table.Value, error) {
+	if decimal, isDecimal := like.(value.DecimalValue); isDecimal {
+		return value.NewDecimalValueFromRat(r, decimal.Scale())
+	}
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return nil, fmt.Errorf("result is out of int range")
+	}
+
+	return value.NewIntValueFromInt64(r.Num().Int64()), nil
+}
+
+// toInt возвращает целое значение числового аргумента
+func toInt(val table.Value) (int64, error) {
+	switch v := val.Value().(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+	case *big.Rat:
+		if v.IsInt() && v.Num().IsInt64() {
+			return v.Num().Int64(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("'%v' should be an integer", val)
+}
+
+func clamp(val, max int64) int64 {
+	switch {
+	case val < 0:
+		return 0
+	case val > max:
+		return max
+	}
+
+	return val
+}
+
+func abs(val int64) int64 {
+	if val < 0 {
+		return -val
+	}
+
+	return val
+}
@@ -0,0 +1,79 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ambiguousColumn - индекс наименования поля, которое встречается в нескольких соединённых таблицах
+const ambiguousColumn = -1
+
+type JoinType string
+
+const (
+	JoinTypeInner JoinType = "inner"
+	JoinTypeLeft  JoinType = "left"
+)
+
+// JoinKey - поля соединяемых таблиц, значения которых должны совпадать
+type JoinKey struct {
+	Left  string
+	Right string
+}
+
+// Qualify возвращает таблицу, поля которой доступны по наименованию '<alias>.<поле>'
+func (t Table) Qualify(alias string) Table {
+	cols := make([]Column, len(t.Columns))
+	for i, col := range t.Columns {
+		cols[i] = col
+		cols[i].Field.Table = alias
+	}
+
+	return NewTable(t.Name, cols)
+}
+
+// HashKey возвращает строку, которая совпадает для равных значений: числа разных типов
+// сравниваются по величине. Для отсутствующего значения возвращается false.
+func HashKey(val Value) (string, bool) {
+	switch v := val.Value().(type) {
+	case nil:
+		return "", false
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "f:" + strconv.FormatFloat(v, 'g', -1, 64), true
+		}
+	case string:
+		return "s:" + v, true
+	case bool:
+		return "b:" + strconv.FormatBool(v), true
+	case time.Time:
+		// UnixNano переполняется за пределами 1678-2262 годов, поэтому секунды и наносекунды хранятся отдельно
+		return "t:" + strconv.FormatInt(v.Unix(), 10) + "." + strconv.Itoa(v.Nanosecond()), true
+	}
+	if r, valid := ToRat(val.Value()); valid {
+		return "n:" + r.RatString(), true
+	}
+
+	return fmt.Sprintf("%T:%v", val.Value(), val.Value()), true
+}
+
+// RowKey собирает ключ строки из значений полей cols. Пустые значения совпадают друг с другом,
+// но не с другими значениями. Если хотя бы одно значение пустое, возвращается false.
+func RowKey(cols []Column, rowIndex int) (string, bool) {
+	valid := true
+	parts := make([]string, 0, len(cols))
+	for _, col := range cols {
+		key, isValue := HashKey(col.Values[rowIndex])
+		if !isValue {
+			// Ключи значений содержат ':', поэтому пустое значение не совпадёт ни с одним из них
+			key = "null"
+			valid = false
+		}
+		parts = append(parts, key)
+	}
+
+	return strings.Join(parts, "\x00"), valid
+}
@@ -0,0 +1,99 @@
+package join
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+// Join соединяет строки таблиц left и right, у которых совпадают значения ключей.
+// Строки right собираются в хеш-таблицу, после чего строки left просматриваются один раз.
+// При JoinTypeLeft строки left без пары дополняются пустыми значениями.
+func Join(ctx context.Context, left, right table.Table, joinType table.JoinType, keys []table.JoinKey) (table.Table, error) {
+	leftKeys, rightKeys, err := joinKeyColumns(left, right, keys)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	hashed := make(map[string][]int, right.RowCount())
+	for rowIndex := 0; rowIndex < right.RowCount(); rowIndex++ {
+		select {
+		case <-ctx.Done():
+			return table.Table{}, ctx.Err()
+		default:
+		}
+		if key, valid := table.RowKey(rightKeys, rowIndex); valid {
+			hashed[key] = append(hashed[key], rowIndex)
+		}
+	}
+
+	cols := make([]table.Column, 0, len(left.Columns)+len(right.Columns))
+	for _, col := range left.Columns {
+		cols = append(cols, table.Column{Field: col.Field})
+	}
+	for _, col := range right.Columns {
+		field := col.Field
+		field.Nullable = field.Nullable || joinType == table.JoinTypeLeft
+		cols = append(cols, table.Column{Field: field})
+	}
+
+	appendRow := func(leftIndex, rightIndex int) {
+		for i, col := range left.Columns {
+			cols[i].Values = append(cols[i].Values, col.Values[leftIndex])
+		}
+		for i, col := range right.Columns {
+			var val table.Value = value.NewNullValue()
+			if rightIndex >= 0 {
+				val = col.Values[rightIndex]
+			}
+			cols[len(left.Columns)+i].Values = append(cols[len(left.Columns)+i].Values, val)
+		}
+	}
+	for rowIndex := 0; rowIndex < left.RowCount(); rowIndex++ {
+		select {
+		case <-ctx.Done():
+			return table.Table{}, ctx.Err()
+		default:
+		}
+		var matched []int
+		if key, valid := table.RowKey(leftKeys, rowIndex); valid {
+			matched = hashed[key]
+		}
+		for _, rightIndex := range matched {
+			appendRow(rowIndex, rightIndex)
+		}
+		if len(matched) == 0 && joinType == table.JoinTypeLeft {
+			appendRow(rowIndex, -1)
+		}
+	}
+
+	return table.NewTable(left.Name, cols), nil
+}
+
+// joinKeyColumns находит поля ключей в соединяемых таблицах. Поля условия могут быть указаны в любом порядке.
+func joinKeyColumns(left, right table.Table, keys []table.JoinKey) ([]table.Column, []table.Column, error) {
+	leftKeys := make([]table.Column, 0, len(keys))
+	rightKeys := make([]table.Column, 0, len(keys))
+	for _, key := range keys {
+		leftCol, leftErr := left.GetColumnByName(key.Left)
+		rightCol, rightErr := right.GetColumnByName(key.Right)
+		if leftErr != nil || rightErr != nil {
+			leftCol, leftErr = left.GetColumnByName(key.Right)
+			rightCol, rightErr = right.GetColumnByName(key.Left)
+		}
+		if leftErr != nil || rightErr != nil {
+			return nil, nil, fmt.Errorf("join condition %s = %s should compare fields of joined tables", key.Left, key.Right)
+		}
+
+		leftType, rightType := leftCol.Field.Type, rightCol.Field.Type
+		if leftType != rightType && !(leftType.IsNumeric() && rightType.IsNumeric()) {
+			return nil, nil, fmt.Errorf("cannot join %s field %s with %s field %s", leftType, key.Left, rightType, key.Right)
+		}
+		leftKeys = append(leftKeys, leftCol)
+		rightKeys = append(rightKeys, rightCol)
+	}
+
+	return leftKeys, rightKeys, nil
+}
@@ -0,0 +1,109 @@
+package join
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoin(t *testing.T) {
+	sales := table.NewTable("sales", []table.Column{
+		{
+			Field: table.Field{Name: "region", Type: table.FieldTypeString, Nullable: true},
+			Values: []table.Value{
+				value.NewStringValue("Europe"), value.NewStringValue("Africa"),
+				value.NewStringValue("Asia"), value.NewNullValue(),
+			},
+		},
+		{
+			Field: table.Field{Name: "units", Type: table.FieldTypeInt},
+			Values: []table.Value{
+				value.NewIntValueFromInt64(10), value.NewIntValueFromInt64(7),
+				value.NewIntValueFromInt64(5), value.NewIntValueFromInt64(1),
+			},
+		},
+	}).Qualify("s")
+	asia1, _ := value.NewDecimalValue("1.00", 2)
+	europe, _ := value.NewDecimalValue("10.00", 2)
+	asia2, _ := value.NewDecimalValue("5.00", 2)
+	regions := table.NewTable("regions", []table.Column{
+		{
+			Field: table.Field{Name: "region", Type: table.FieldTypeString},
+			Values: []table.Value{
+				value.NewStringValue("Asia"), value.NewStringValue("Europe"), value.NewStringValue("Asia"),
+			},
+		},
+		{
+			Field:  table.Field{Name: "units", Type: table.FieldTypeDecimal, Scale: 2},
+			Values: []table.Value{asia1, europe, asia2},
+		},
+	}).Qualify("r")
+
+	tests := []struct {
+		name     string
+		joinType table.JoinType
+		keys     []table.JoinKey
+		want     [][]string
+	}{
+		{
+			name:     "inner",
+			joinType: table.JoinTypeInner,
+			keys:     []table.JoinKey{{Left: "s.region", Right: "r.region"}},
+			want: [][]string{
+				{"Europe", "10", "Europe", "10.00"},
+				{"Asia", "5", "Asia", "1.00"},
+				{"Asia", "5", "Asia", "5.00"},
+			},
+		},
+		{
+			name:     "left keeps rows without pair",
+			joinType: table.JoinTypeLeft,
+			keys:     []table.JoinKey{{Left: "r.region", Right: "s.region"}},
+			want: [][]string{
+				{"Europe", "10", "Europe", "10.00"},
+				{"Africa", "7", "NULL", "NULL"},
+				{"Asia", "5", "Asia", "1.00"},
+				{"Asia", "5", "Asia", "5.00"},
+				{"NULL", "1", "NULL", "NULL"},
+			},
+		},
+		{
+			name:     "numbers of different types",
+			joinType: table.JoinTypeInner,
+			keys:     []table.JoinKey{{Left: "s.region", Right: "r.region"}, {Left: "s.units", Right: "r.units"}},
+			want: [][]string{
+				{"Europe", "10", "Europe", "10.00"},
+				{"Asia", "5", "Asia", "5.00"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Join(context.Background(), sales, regions, tt.joinType, tt.keys)
+			assert.NoError(t, err)
+
+			rows := make([][]string, 0, got.RowCount())
+			for _, rowIndex := range got.RowIndexes() {
+				row := make([]string, 0, len(got.Columns))
+				for _, col := range got.Columns {
+					row = append(row, col.Values[rowIndex].String())
+				}
+				rows = append(rows, row)
+			}
+			assert.Equal(t, tt.want, rows)
+
+			units, err := got.GetColumnByName("r.units")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.joinType == table.JoinTypeLeft, units.Field.Nullable)
+			_, err = got.GetColumnByName("units")
+			assert.EqualError(t, err, "column 'units' is ambiguous")
+		})
+	}
+
+	_, err := Join(context.Background(), sales, regions, table.JoinTypeInner, []table.JoinKey{{Left: "s.region", Right: "r.units"}})
+	assert.EqualError(t, err, "cannot join string field s.region with decimal field r.units")
+}
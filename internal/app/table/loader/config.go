@@ -1,24 +1,32 @@
 package loader
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
 
 	"gopkg.in/yaml.v3"
 )
 
+const defaultDecimalScale = 2
+
 type field struct {
-	Name string `yaml:"name"`
-	Type string `yaml:"type"`
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Nullable bool   `yaml:"nullable,omitempty" default:"false"`
+	Layout   string `yaml:"layout,omitempty"`
+	Scale    *int   `yaml:"scale,omitempty"`
 }
 
 type tableConfig struct {
-	Name       string  `yaml:"name"`
-	Sep        string  `yaml:"sep" default:";"`
-	LazyQuotes bool    `yaml:"lazyQuotes" default:"false"`
-	Fields     []field `yaml:"fields"`
+	Name       string   `yaml:"name"`
+	Sep        string   `yaml:"sep" default:";"`
+	LazyQuotes bool     `yaml:"lazyQuotes" default:"false"`
+	NullValues []string `yaml:"nullValues,omitempty"`
+	Fields     []field  `yaml:"fields"`
 }
 
 func (c tableConfig) getSep() rune {
@@ -34,23 +42,67 @@ func (c tableConfig) getFields() ([]table.Field, error) {
 
 	for _, f := range c.Fields {
 		var t table.FieldType
-		if f.Type == "number" {
+		layout := f.Layout
+		scale := 0
+		switch f.Type {
+		case "number":
 			t = table.FieldTypeNumber
-		} else if f.Type == "string" {
+		case "string":
 			t = table.FieldTypeString
-		} else {
+		case "date":
+			t = table.FieldTypeDate
+			if layout == "" {
+				layout = value.DefaultDateLayout
+			}
+		case "datetime":
+			t = table.FieldTypeDatetime
+			if layout == "" {
+				layout = value.DefaultDatetimeLayout
+			}
+		case "bool":
+			t = table.FieldTypeBool
+		case "int":
+			t = table.FieldTypeInt
+		case "decimal":
+			t = table.FieldTypeDecimal
+			scale = defaultDecimalScale
+			if f.Scale != nil {
+				scale = *f.Scale
+			}
+			if scale < 0 || scale > value.MaxDecimalScale {
+				return nil, fmt.Errorf("scale of '%s' should be from 0 to %d", f.Name, value.MaxDecimalScale)
+			}
+		default:
 			return nil, fmt.Errorf("unknown type '%s'", f.Type)
 		}
 
 		ret = append(ret, table.Field{
-			Name: f.Name,
-			Type: t,
+			Name:     f.Name,
+			Type:     t,
+			Nullable: f.Nullable,
+			Layout:   layout,
+			Scale:    scale,
 		})
 	}
 
 	return ret, nil
 }
 
+// getNullValues возвращает значения, которые в полях nullable считаются отсутствующими
+func (c tableConfig) getNullValues() map[string]struct{} {
+	nullValues := c.NullValues
+	if nullValues == nil {
+		nullValues = []string{""}
+	}
+
+	ret := make(map[string]struct{}, len(nullValues))
+	for _, v := range nullValues {
+		ret[v] = struct{}{}
+	}
+
+	return ret
+}
+
 func loadConfig(file io.Reader) (tableConfig, error) {
 	tc := tableConfig{}
 	if err := yaml.NewDecoder(file).Decode(&tc); err != nil {
@@ -66,3 +118,41 @@ func loadConfig(file io.Reader) (tableConfig, error) {
 
 	return tc, nil
 }
+
+// MarshalConfig описывает таблицу в формате yaml-файла, пригодного для повторной загрузки
+func MarshalConfig(t table.Table) ([]byte, error) {
+	config := tableConfig{
+		Name: t.Name,
+		Sep:  ",",
+	}
+	if t.Source != nil {
+		config.Sep = string(t.Source.Sep)
+		config.LazyQuotes = t.Source.LazyQuotes
+		config.NullValues = t.Source.NullValues
+	}
+
+	for _, col := range t.Columns {
+		f := field{
+			Name:     col.Field.Name,
+			Type:     col.Field.Type.String(),
+			Nullable: col.Field.Nullable,
+		}
+		switch col.Field.Type {
+		case table.FieldTypeDate, table.FieldTypeDatetime:
+			f.Layout = col.Field.Layout
+		case table.FieldTypeDecimal:
+			scale := col.Field.Scale
+			f.Scale = &scale
+		}
+		config.Fields = append(config.Fields, f)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return nil, fmt.Errorf("error when encode config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,140 @@
+package loader
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+// sampleSize - число строк, по которым определяются типы полей
+const sampleSize = 1000
+
+// LoadWithInference загружает csv-файл без yaml-описания: разделитель и типы полей
+// определяются по содержимому файла. Если tableName пустое, таблица называется по имени файла.
+func LoadWithInference(csvPath string, tableName string) (table.Table, error) {
+	if tableName == "" {
+		tableName = strings.TrimSuffix(filepath.Base(csvPath), filepath.Ext(csvPath))
+	}
+
+	sep, err := sniffSep(csvPath)
+	if err != nil {
+		return table.Table{}, err
+	}
+	config := tableConfig{Name: tableName, Sep: string(sep)}
+
+	header, records, err := readRecords(csvPath, sep, config.LazyQuotes)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	sample := records
+	if len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	t, err := makeTable(tableName, header, records, config, inferFields(header, sample))
+	if err != nil && len(sample) < len(records) {
+		// Строки за пределами выборки не подошли под найденные типы, поэтому определяем их по всему файлу
+		t, err = makeTable(tableName, header, records, config, inferFields(header, records))
+	}
+
+	return t, err
+}
+
+// sniffSep выбирает разделитель, который чаще других встречается в заголовке вне кавычек
+func sniffSep(csvPath string) (rune, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	header, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && header == "" {
+		return 0, fmt.Errorf("empty file")
+	}
+
+	counts := make(map[rune]int)
+	quoted := false
+	for _, r := range header {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if !quoted {
+			counts[r]++
+		}
+	}
+
+	ret := ','
+	for _, sep := range []rune{',', ';', '\t', '|'} {
+		if counts[sep] > counts[ret] {
+			ret = sep
+		}
+	}
+
+	return ret, nil
+}
+
+// candidateFields - типы полей в порядке предпочтения, string подходит для любых значений
+func candidateFields() []table.Field {
+	return []table.Field{
+		{Type: table.FieldTypeInt},
+		{Type: table.FieldTypeNumber},
+		{Type: table.FieldTypeBool},
+		{Type: table.FieldTypeDate, Layout: value.DefaultDateLayout},
+		{Type: table.FieldTypeDate, Layout: "02.01.2006"},
+		{Type: table.FieldTypeDatetime, Layout: value.DefaultDatetimeLayout},
+		{Type: table.FieldTypeDatetime, Layout: time.RFC3339},
+		{Type: table.FieldTypeString},
+	}
+}
+
+func inferFields(header []string, records [][]string) []table.Field {
+	ret := make([]table.Field, 0, len(header))
+	for columnIndex, name := range header {
+		ret = append(ret, inferField(name, records, columnIndex))
+	}
+
+	return ret
+}
+
+// inferField отбрасывает типы, которым не соответствует хотя бы одно значение, и выбирает первый из оставшихся.
+// Пустые значения не влияют на тип, но делают поле nullable.
+func inferField(name string, records [][]string, columnIndex int) table.Field {
+	candidates := candidateFields()
+	nullable := false
+	filled := false
+
+	for _, record := range records {
+		raw := record[columnIndex]
+		if raw == "" {
+			nullable = true
+
+			continue
+		}
+		filled = true
+
+		remaining := candidates[:0]
+		for _, candidate := range candidates {
+			if _, err := parseValue(candidate, raw, nil); err == nil {
+				remaining = append(remaining, candidate)
+			}
+		}
+		candidates = remaining
+	}
+
+	ret := candidates[0]
+	if !filled {
+		ret = table.Field{Type: table.FieldTypeString}
+	}
+	ret.Name = name
+	// Пустая строка - допустимое значение строкового поля
+	ret.Nullable = nullable && ret.Type != table.FieldTypeString
+
+	return ret
+}
@@ -0,0 +1,128 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferFields(t *testing.T) {
+	header := []string{"id", "price", "name", "born", "active", "empty"}
+	records := [][]string{
+		{"1", "10.5", "Mike", "2020-01-02", "true", ""},
+		{"2", "", "", "1999-12-31", "FALSE", ""},
+		{"3", "7", "Anna", "2001-05-17", "t", ""},
+	}
+
+	want := []table.Field{
+		{Name: "id", Type: table.FieldTypeInt},
+		{Name: "price", Type: table.FieldTypeNumber, Nullable: true},
+		{Name: "name", Type: table.FieldTypeString},
+		{Name: "born", Type: table.FieldTypeDate, Layout: value.DefaultDateLayout},
+		{Name: "active", Type: table.FieldTypeBool},
+		{Name: "empty", Type: table.FieldTypeString},
+	}
+
+	assert.Equal(t, want, inferFields(header, records))
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestSniffSep(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    rune
+	}{
+		{name: "comma", content: "id,name,price\n1,Pen,10\n", want: ','},
+		{name: "semicolon", content: "id;name;price\n1;Pen;10\n", want: ';'},
+		{name: "tab", content: "id\tname\tprice\n1\tPen\t10\n", want: '\t'},
+		{name: "quoted separator", content: "\"id,code\";\"a,b,c\";name\n1;x;Pen\n", want: ';'},
+		{name: "single column", content: "name\nPen\n", want: ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sniffSep(writeFile(t, "items.csv", tt.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := sniffSep(writeFile(t, "empty.csv", ""))
+	assert.EqualError(t, err, "empty file")
+}
+
+func TestLoadWithInference(t *testing.T) {
+	path := writeFile(t, "sales.csv", "id;region;units\n1;Europe;10\n2;Asia;\n")
+
+	tbl, err := LoadWithInference(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "sales", tbl.Name)
+	assert.Equal(t, ';', tbl.Source.Sep)
+	assert.Equal(t, table.Field{Name: "units", Type: table.FieldTypeInt, Nullable: true}, tbl.Columns[2].Field)
+
+	tbl, err = LoadWithInference(path, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", tbl.Name)
+}
+
+func TestLoadWithInference_TypeChangesAfterSample(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,code\n")
+	for i := 0; i < sampleSize; i++ {
+		fmt.Fprintf(&sb, "%d,%d\n", i, i)
+	}
+	fmt.Fprintf(&sb, "%d,A-%d\n", sampleSize, sampleSize)
+
+	tbl, err := LoadWithInference(writeFile(t, "codes.csv", sb.String()), "")
+	assert.NoError(t, err)
+	assert.Equal(t, table.Field{Name: "id", Type: table.FieldTypeInt}, tbl.Columns[0].Field)
+	assert.Equal(t, table.Field{Name: "code", Type: table.FieldTypeString}, tbl.Columns[1].Field)
+	assert.Equal(t, sampleSize+1, tbl.RowCount())
+	assert.Equal(t, "A-1000", tbl.Columns[1].Values[sampleSize].String())
+}
+
+func TestMarshalConfig(t *testing.T) {
+	fields := []table.Field{
+		{Name: "id", Type: table.FieldTypeInt},
+		{Name: "price", Type: table.FieldTypeDecimal, Scale: 3, Nullable: true},
+		{Name: "rate", Type: table.FieldTypeNumber},
+		{Name: "sold", Type: table.FieldTypeDate, Layout: "02.01.2006"},
+		{Name: "updated", Type: table.FieldTypeDatetime, Layout: value.DefaultDatetimeLayout, Nullable: true},
+		{Name: "active", Type: table.FieldTypeBool},
+		{Name: "name", Type: table.FieldTypeString},
+	}
+	cols := make([]table.Column, 0, len(fields))
+	for _, f := range fields {
+		cols = append(cols, table.Column{Field: f})
+	}
+	tbl := table.NewTable("items", cols)
+	tbl.Source = &table.Source{Sep: '\t', LazyQuotes: true, NullValues: []string{"", "n/a"}}
+
+	data, err := MarshalConfig(tbl)
+	assert.NoError(t, err)
+
+	config, err := loadConfig(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "items", config.Name)
+	assert.Equal(t, '\t', config.getSep())
+	assert.True(t, config.LazyQuotes)
+	assert.Equal(t, []string{"", "n/a"}, config.NullValues)
+
+	got, err := config.getFields()
+	assert.NoError(t, err)
+	assert.Equal(t, fields, got)
+}
@@ -24,7 +24,7 @@ func LoadFromCSV(csvPath string, configPath string) (table.Table, error) {
 		return table.Table{}, err
 	}
 
-	t, err := load(tableConfig.Name, csvPath, tableConfig.getSep(), tableConfig.LazyQuotes, fields)
+	t, err := load(tableConfig.Name, csvPath, tableConfig, fields)
 	if err != nil {
 		return table.Table{}, err
 	}
@@ -32,32 +32,50 @@ func LoadFromCSV(csvPath string, configPath string) (table.Table, error) {
 	return t, nil
 }
 
-func load(tableName string, path string, sep rune, lazyQuotes bool, fields []table.Field) (table.Table, error) {
-	file, err := os.Open(path)
+func load(tableName string, path string, config tableConfig, fields []table.Field) (table.Table, error) {
+	header, records, err := readRecords(path, config.getSep(), config.LazyQuotes)
 	if err != nil {
 		return table.Table{}, err
 	}
 
+	return makeTable(tableName, header, records, config, fields)
+}
+
+func readRecords(path string, sep rune, lazyQuotes bool) ([]string, [][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
 	reader := csv.NewReader(file)
 	reader.Comma = sep
 	reader.LazyQuotes = lazyQuotes
 
 	records, err := reader.ReadAll()
 	if err != nil {
-		return table.Table{}, err
+		return nil, nil, err
 	}
 	if len(records) == 0 {
-		return table.Table{}, fmt.Errorf("empty file")
+		return nil, nil, fmt.Errorf("empty file")
 	}
 
-	header := records[0]
-	records = records[1:]
+	return records[0], records[1:], nil
+}
 
+func makeTable(
+	tableName string,
+	header []string,
+	records [][]string,
+	config tableConfig,
+	fields []table.Field,
+) (table.Table, error) {
 	fieldMap := make(map[string]int)
 	for i, fieldName := range header {
 		fieldMap[fieldName] = i
 	}
 
+	nullValues := config.getNullValues()
 	cols := make([]table.Column, 0, len(fields))
 
 	for _, field := range fields {
@@ -69,22 +87,44 @@ func load(tableName string, path string, sep rune, lazyQuotes bool, fields []tab
 		col := table.Column{Field: field}
 
 		for rowIndex := 0; rowIndex < len(records); rowIndex++ {
-			switch field.Type {
-			case table.FieldTypeNumber:
-				val, err := value.NewNumberValue(records[rowIndex][columnIndex])
-				if err != nil {
-					return table.Table{}, fmt.Errorf("error when parsing column %s, line %d: %w", field.Name, rowIndex+2, err)
-				}
-				col.Values = append(col.Values, val)
-			case table.FieldTypeString:
-				val := value.NewStringValue(records[rowIndex][columnIndex])
-				col.Values = append(col.Values, val)
-			default:
-				return table.Table{}, fmt.Errorf("unknown field type for %s", field.Name)
+			val, err := parseValue(field, records[rowIndex][columnIndex], nullValues)
+			if err != nil {
+				return table.Table{}, fmt.Errorf("error when parsing column %s, line %d: %w", field.Name, rowIndex+2, err)
 			}
+			col.Values = append(col.Values, val)
 		}
 		cols = append(cols, col)
 	}
 
-	return table.NewTable(tableName, cols), nil
+	t := table.NewTable(tableName, cols)
+	t.Source = &table.Source{
+		Sep:        config.getSep(),
+		LazyQuotes: config.LazyQuotes,
+		NullValues: config.NullValues,
+	}
+
+	return t, nil
+}
+
+func parseValue(field table.Field, raw string, nullValues map[string]struct{}) (table.Value, error) {
+	if _, found := nullValues[raw]; found && field.Nullable {
+		return value.NewNullValue(), nil
+	}
+
+	switch field.Type {
+	case table.FieldTypeNumber:
+		return value.NewNumberValue(raw)
+	case table.FieldTypeString:
+		return value.NewStringValue(raw), nil
+	case table.FieldTypeDate, table.FieldTypeDatetime:
+		return value.NewTimeValue(raw, field.Layout)
+	case table.FieldTypeBool:
+		return value.NewBoolValue(raw)
+	case table.FieldTypeInt:
+		return value.NewIntValue(raw)
+	case table.FieldTypeDecimal:
+		return value.NewExactDecimalValue(raw, field.Scale)
+	}
+
+	return nil, fmt.Errorf("unknown field type for %s", field.Name)
 }
@@ -0,0 +1,26 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFromCSV_DecimalScale(t *testing.T) {
+	config := writeFile(t, "prices.yaml", `name: prices
+sep: ","
+fields:
+  - name: price
+    type: decimal
+    scale: 2
+`)
+
+	tbl, err := LoadFromCSV(writeFile(t, "prices.csv", "price\n1.5\n2.2500\n"), config)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.50", tbl.Columns[0].Values[0].String())
+	assert.Equal(t, "2.25", tbl.Columns[0].Values[1].String())
+
+	_, err = LoadFromCSV(writeFile(t, "prices.csv", "price\n1.5\n2.255\n"), config)
+	assert.EqualError(t, err,
+		"error when parsing column price, line 3: '2.255' has more than 2 digits after the decimal point")
+}
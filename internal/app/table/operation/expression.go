@@ -0,0 +1,134 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+)
+
+var _ table.LogicalOperation = ExpressionOperation{}
+
+// NewExpressionOperation создаёт условие, которое проверяется и компилируется один раз для таблицы,
+// а не для каждой строки в Match
+func NewExpressionOperation(expr table.Expression) ExpressionOperation {
+	return ExpressionOperation{Expression: expr, compiled: &compiledCondition{}}
+}
+
+// ExpressionOperation отбирает строки, для которых условие Expression истинно,
+// например сравнение двух полей: total_revenue > total_cost
+type ExpressionOperation struct {
+	Expression table.Expression
+	// compiled - условие, скомпилированное для полей последней таблицы, общее для копий операции.
+	// Если операция создана без NewExpressionOperation, условие компилируется при каждом вызове.
+	compiled *compiledCondition
+}
+
+// compiledCondition хранит скомпилированное условие и поля таблицы, для которых оно скомпилировано
+type compiledCondition struct {
+	fields []table.Field
+	expr   table.Expression
+	// not - кэш для отрицания условия
+	not *compiledCondition
+}
+
+func (c *compiledCondition) negation() *compiledCondition {
+	if c == nil {
+		return nil
+	}
+	if c.not == nil {
+		c.not = &compiledCondition{}
+	}
+
+	return c.not
+}
+
+func (o ExpressionOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	expr, err := o.compile(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []int
+	for i := 0; i < t.RowCount(); i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		val, err := expr.Evaluate(t, i)
+		if err != nil {
+			return nil, err
+		}
+		if val.Value() == true {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret, nil
+}
+
+// Match проверяет тип условия так же, как Apply, потому что вызывается вместо него, например, в LimitOperation
+func (o ExpressionOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	expr, err := o.compile(t)
+	if err != nil {
+		return false, err
+	}
+	val, err := expr.Evaluate(t, rowIndex)
+	if err != nil {
+		return false, err
+	}
+
+	return val.Value() == true, nil
+}
+
+// compile проверяет тип условия и компилирует его, если оно ещё не скомпилировано для полей таблицы t
+func (o ExpressionOperation) compile(t table.Table) (table.Expression, error) {
+	if o.compiled != nil && o.compiled.expr != nil && sameFields(o.compiled.fields, t.Columns) {
+		return o.compiled.expr, nil
+	}
+
+	if err := o.check(t); err != nil {
+		return nil, err
+	}
+	expr, err := expression.Compile(o.Expression, t)
+	if err != nil {
+		return nil, err
+	}
+	if o.compiled != nil {
+		o.compiled.fields = make([]table.Field, 0, len(t.Columns))
+		for _, col := range t.Columns {
+			o.compiled.fields = append(o.compiled.fields, col.Field)
+		}
+		o.compiled.expr = expr
+	}
+
+	return expr, nil
+}
+
+func (o ExpressionOperation) check(t table.Table) error {
+	field, err := o.Expression.Field(t)
+	if err != nil {
+		return err
+	}
+	if field.Type != table.FieldTypeBool {
+		return fmt.Errorf("condition '%s' should be bool, got %s", o.Expression, field.Type)
+	}
+
+	return nil
+}
+
+func sameFields(fields []table.Field, cols []table.Column) bool {
+	if len(fields) != len(cols) {
+		return false
+	}
+	for i, col := range cols {
+		if fields[i] != col.Field {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,118 @@
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressionOperation_Apply(t *testing.T) {
+	tbl := table.NewTable("sales", []table.Column{
+		{
+			Field: table.Field{Name: "revenue", Type: table.FieldTypeInt},
+			Values: []table.Value{
+				value.NewIntValueFromInt64(100), value.NewIntValueFromInt64(50), value.NewIntValueFromInt64(70),
+			},
+		},
+		{
+			Field: table.Field{Name: "cost", Type: table.FieldTypeInt, Nullable: true},
+			Values: []table.Value{
+				value.NewIntValueFromInt64(80), value.NewIntValueFromInt64(60), value.NewNullValue(),
+			},
+		},
+	})
+	profitable := ExpressionOperation{Expression: expression.CompareExpression{
+		Type:  table.CompareOperationTypeMore,
+		Left:  expression.ColumnExpression{ColumnName: "revenue"},
+		Right: expression.ColumnExpression{ColumnName: "cost"},
+	}}
+
+	tests := []struct {
+		name string
+		op   table.LogicalOperation
+		want []int
+	}{
+		{
+			name: "two columns",
+			op:   profitable,
+			want: []int{0},
+		},
+		{
+			name: "unknown comparison is not negated",
+			op:   NotOperation{Operation: profitable},
+			want: []int{1},
+		},
+		{
+			name: "computed operand",
+			op: ExpressionOperation{Expression: expression.CompareExpression{
+				Type: table.CompareOperationTypeMore,
+				Left: expression.ArithmeticExpression{
+					Type:  table.ArithmeticOperationTypeMinus,
+					Left:  expression.ColumnExpression{ColumnName: "revenue"},
+					Right: expression.ColumnExpression{ColumnName: "cost"},
+				},
+				Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(10)},
+			}},
+			want: []int{0},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Apply(ctx, tbl)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, tt.want, got)
+
+			for rowIndex := 0; rowIndex < tbl.RowCount(); rowIndex++ {
+				match, err := tt.op.Match(tbl, rowIndex)
+				assert.ErrorIs(t, err, nil)
+				assert.Equal(t, contains(tt.want, rowIndex), match)
+			}
+		})
+	}
+
+	notBool := ExpressionOperation{Expression: expression.ColumnExpression{ColumnName: "revenue"}}
+	_, err := notBool.Apply(ctx, tbl)
+	assert.EqualError(t, err, "condition 'revenue' should be bool, got int")
+	_, err = LimitOperation{Operation: notBool, Limit: 1}.Apply(ctx, tbl)
+	assert.EqualError(t, err, "condition 'revenue' should be bool, got int")
+}
+
+func TestExpressionOperation_CompileOnce(t *testing.T) {
+	newTable := func(fieldType table.FieldType, val table.Value) table.Table {
+		return table.NewTable("sales", []table.Column{
+			{Field: table.Field{Name: "units", Type: fieldType}, Values: []table.Value{val, val}},
+		})
+	}
+	ints := newTable(table.FieldTypeInt, value.NewIntValueFromInt64(10))
+	strings := newTable(table.FieldTypeString, value.NewStringValue("10"))
+	op := NewExpressionOperation(expression.CompareExpression{
+		Type:  table.CompareOperationTypeEqual,
+		Left:  expression.ColumnExpression{ColumnName: "units"},
+		Right: expression.ConstExpression{Value: value.NewIntValueFromInt64(10)},
+	})
+
+	match, err := op.Match(ints, 0)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	compiled := op.compiled.expr
+	match, err = op.Match(ints, 1)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.Equal(t, compiled, op.compiled.expr)
+
+	// Для таблицы с другими полями условие проверяется заново
+	_, err = op.Match(strings, 0)
+	assert.Error(t, err)
+
+	got, err := LimitOperation{Operation: NotOperation{Operation: op}, Limit: 1}.Apply(context.Background(), ints)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NotNil(t, op.compiled.not.expr)
+}
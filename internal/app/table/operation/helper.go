@@ -1,5 +1,11 @@
 package operation
 
+import (
+	"context"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
 // mergeIndexes объединяет два слайса, сохраняя их порядок и исключая дубликаты
 func mergeIndexes(first []int, second []int) []int {
 
@@ -56,3 +62,49 @@ func mergeIndexes(first []int, second []int) []int {
 
 	return ret
 }
+
+// applyToColumn возвращает индексы строк, значения поля которых прошли проверку
+func applyToColumn(
+	ctx context.Context,
+	t table.Table,
+	columnName string,
+	check func(val table.Value) (bool, error),
+) ([]int, error) {
+	column, err := t.GetColumnByName(columnName)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []int
+	for i, val := range column.Values {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		accept, err := check(val)
+		if err != nil {
+			return nil, err
+		}
+		if accept {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret, nil
+}
+
+func matchColumn(
+	t table.Table,
+	columnName string,
+	rowIndex int,
+	check func(val table.Value) (bool, error),
+) (bool, error) {
+	column, err := t.GetColumnByName(columnName)
+	if err != nil {
+		return false, err
+	}
+
+	return check(column.Values[rowIndex])
+}
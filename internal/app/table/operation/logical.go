@@ -2,14 +2,18 @@ package operation
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
 	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
 )
 
 var _ table.LogicalOperation = AndOperation{}
 var _ table.LogicalOperation = OrOperation{}
 var _ table.LogicalOperation = DummyValueOperation{}
+var _ table.LogicalOperation = NotOperation{}
+var _ table.LogicalOperation = LimitOperation{}
 
 type AndOperation struct {
 	Left  table.LogicalOperation
@@ -47,6 +51,15 @@ func (o AndOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
 	return ret, nil
 }
 
+func (o AndOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	accept, err := o.Left.Match(t, rowIndex)
+	if err != nil || !accept {
+		return false, err
+	}
+
+	return o.Right.Match(t, rowIndex)
+}
+
 type OrOperation struct {
 	Left  table.LogicalOperation
 	Right table.LogicalOperation
@@ -73,6 +86,121 @@ func (o OrOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
 	return mergeIndexes(res1, res2), nil
 }
 
+func (o OrOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	accept, err := o.Left.Match(t, rowIndex)
+	if err != nil || accept {
+		return accept, err
+	}
+
+	return o.Right.Match(t, rowIndex)
+}
+
+// NotOperation возвращает строки таблицы, для которых вложенная операция ложна.
+// Строки, результат проверки которых неизвестен из-за отсутствующих значений, не возвращаются.
+type NotOperation struct {
+	Operation table.LogicalOperation
+}
+
+func (o NotOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	return negate(o.Operation).Apply(ctx, t)
+}
+
+func (o NotOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	return negate(o.Operation).Match(t, rowIndex)
+}
+
+// negate вносит отрицание внутрь логических операций до сравнений, результат
+// которых неизвестен, если значение поля отсутствует
+func negate(op table.LogicalOperation) table.LogicalOperation {
+	switch o := op.(type) {
+	case NotOperation:
+		return o.Operation
+	case AndOperation:
+		return OrOperation{Left: negate(o.Left), Right: negate(o.Right)}
+	case OrOperation:
+		return AndOperation{Left: negate(o.Left), Right: negate(o.Right)}
+	case DummyValueOperation:
+		if o.CompareOperation.Type == table.CompareOperationTypeDummy {
+			return complementOperation{Operation: o}
+		}
+
+		return complementOperation{Operation: o, ColumnNames: []string{o.CompareOperation.ColumnName}}
+	case InOperation:
+		return complementOperation{Operation: o, ColumnNames: []string{o.ColumnName}}
+	case InSubqueryOperation:
+		return notInSubqueryOperation{In: o}
+	case BetweenOperation:
+		return complementOperation{Operation: o, ColumnNames: []string{o.ColumnName}}
+	case ExpressionOperation:
+		// Отрицание выражения само учитывает неизвестный результат
+		return ExpressionOperation{
+			Expression: expression.NotExpression{Operand: o.Expression},
+			compiled:   o.compiled.negation(),
+		}
+	}
+
+	return complementOperation{Operation: op}
+}
+
+// complementOperation возвращает строки, не попавшие в результат вложенной операции,
+// в которых заполнены значения полей ColumnNames
+type complementOperation struct {
+	Operation   table.LogicalOperation
+	ColumnNames []string
+}
+
+func (o complementOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	res, err := o.Operation.Apply(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	rowCount := t.RowCount()
+	excluded := make([]bool, rowCount)
+	for _, i := range res {
+		excluded[i] = true
+	}
+	for _, name := range o.ColumnNames {
+		column, err := t.GetColumnByName(name)
+		if err != nil {
+			return nil, err
+		}
+		for i, val := range column.Values {
+			if val.Value() == nil {
+				excluded[i] = true
+			}
+		}
+	}
+
+	ret := make([]int, 0, rowCount-len(res))
+	for i := 0; i < rowCount; i++ {
+		if !excluded[i] {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret, nil
+}
+
+func (o complementOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	for _, name := range o.ColumnNames {
+		column, err := t.GetColumnByName(name)
+		if err != nil {
+			return false, err
+		}
+		if column.Values[rowIndex].Value() == nil {
+			return false, nil
+		}
+	}
+
+	accept, err := o.Operation.Match(t, rowIndex)
+	if err != nil {
+		return false, err
+	}
+
+	return !accept, nil
+}
+
 type DummyValueOperation struct {
 	CompareOperation table.CompareValueOperation
 }
@@ -81,21 +209,81 @@ func (o DummyValueOperation) Apply(ctx context.Context, t table.Table) ([]int, e
 	var ret []int
 
 	if o.CompareOperation.Type == table.CompareOperationTypeDummy {
-		ret = make([]int, 0, len(t.Columns[0].Values))
-		for i := range t.Columns[0].Values {
+		return t.RowIndexes(), nil
+	}
+
+	column, err := o.column(t)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, val := range column.Values {
+		accept, err := val.Compare(o.CompareOperation.Val, o.CompareOperation.Type)
+		if err != nil {
+			return nil, err
+		}
+		if accept {
 			ret = append(ret, i)
 		}
+	}
+
+	return ret, nil
+}
 
-		return ret, nil
+func (o DummyValueOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	if o.CompareOperation.Type == table.CompareOperationTypeDummy {
+		return true, nil
+	}
+
+	column, err := o.column(t)
+	if err != nil {
+		return false, err
 	}
 
+	return column.Values[rowIndex].Compare(o.CompareOperation.Val, o.CompareOperation.Type)
+}
+
+// column возвращает поле условия и проверяет, что сравнение с шаблоном применяется к строковому полю
+func (o DummyValueOperation) column(t table.Table) (table.Column, error) {
 	column, err := t.GetColumnByName(o.CompareOperation.ColumnName)
 	if err != nil {
-		return nil, err
+		return table.Column{}, err
 	}
 
-	for i, val := range column.Values {
-		accept, err := val.Compare(o.CompareOperation.Val, o.CompareOperation.Type)
+	switch o.CompareOperation.Type {
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike, table.CompareOperationTypeRegexp:
+		if column.Field.Type != table.FieldTypeString {
+			return table.Column{}, fmt.Errorf("%s is only supported for string fields, '%s' is %s",
+				o.CompareOperation.Type, o.CompareOperation.ColumnName, column.Field.Type)
+		}
+	}
+
+	return column, nil
+}
+
+// LimitOperation проверяет строки по порядку и прекращает просмотр таблицы,
+// как только найдено Limit подходящих строк
+type LimitOperation struct {
+	Operation table.LogicalOperation
+	Limit     int
+}
+
+func (o LimitOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	rowCount := t.RowCount()
+	capacity := o.Limit
+	if capacity > rowCount {
+		capacity = rowCount
+	}
+	ret := make([]int, 0, capacity)
+
+	for i := 0; i < rowCount && len(ret) < o.Limit; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		accept, err := o.Operation.Match(t, i)
 		if err != nil {
 			return nil, err
 		}
@@ -106,3 +294,7 @@ func (o DummyValueOperation) Apply(ctx context.Context, t table.Table) ([]int, e
 
 	return ret, nil
 }
+
+func (o LimitOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	return o.Operation.Match(t, rowIndex)
+}
@@ -0,0 +1,251 @@
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTable() table.Table {
+	ages := []string{"18", "25", "40", "12", "33", "70"}
+	values := make([]table.Value, 0, len(ages))
+	for _, age := range ages {
+		val, _ := value.NewNumberValue(age)
+		values = append(values, val)
+	}
+
+	return table.NewTable("people", []table.Column{
+		{
+			Field:  table.Field{Name: "age", Type: table.FieldTypeNumber},
+			Values: values,
+		},
+	})
+}
+
+func TestLimitOperation_Apply(t *testing.T) {
+	tests := []struct {
+		name string
+		op   table.LogicalOperation
+		want []int
+	}{
+		{
+			name: "stop after limit",
+			op: LimitOperation{
+				Operation: DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						ColumnName: "age",
+						Type:       table.CompareOperationTypeMore,
+						Val:        20.0,
+					},
+				},
+				Limit: 2,
+			},
+			want: []int{1, 2},
+		},
+		{
+			name: "limit more than rows",
+			op: LimitOperation{
+				Operation: AndOperation{
+					Left: DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							ColumnName: "age",
+							Type:       table.CompareOperationTypeMore,
+							Val:        20.0,
+						},
+					},
+					Right: DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							ColumnName: "age",
+							Type:       table.CompareOperationTypeLess,
+							Val:        50.0,
+						},
+					},
+				},
+				Limit: 10,
+			},
+			want: []int{1, 2, 4},
+		},
+		{
+			name: "zero limit",
+			op: LimitOperation{
+				Operation: DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+				Limit: 0,
+			},
+			want: []int{},
+		},
+	}
+
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Apply(ctx, tbl)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNotOperation_Apply(t *testing.T) {
+	tests := []struct {
+		name string
+		op   table.LogicalOperation
+		want []int
+	}{
+		{
+			name: "complement",
+			op: NotOperation{
+				Operation: DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						ColumnName: "age",
+						Type:       table.CompareOperationTypeMore,
+						Val:        20.0,
+					},
+				},
+			},
+			want: []int{0, 3},
+		},
+		{
+			name: "double negation",
+			op: NotOperation{
+				Operation: NotOperation{
+					Operation: DummyValueOperation{
+						CompareOperation: table.CompareValueOperation{
+							ColumnName: "age",
+							Type:       table.CompareOperationTypeLess,
+							Val:        20.0,
+						},
+					},
+				},
+			},
+			want: []int{0, 3},
+		},
+		{
+			name: "not all rows",
+			op: NotOperation{
+				Operation: DummyValueOperation{
+					CompareOperation: table.CompareValueOperation{
+						Type: table.CompareOperationTypeDummy,
+					},
+				},
+			},
+			want: []int{},
+		},
+	}
+
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Apply(ctx, tbl)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNotOperation_ApplyWithNulls(t *testing.T) {
+	age, _ := value.NewNumberValue("18")
+	tbl := table.NewTable("people", []table.Column{
+		{
+			Field:  table.Field{Name: "age", Type: table.FieldTypeNumber, Nullable: true},
+			Values: []table.Value{age, value.NewNullValue()},
+		},
+		{
+			Field:  table.Field{Name: "name", Type: table.FieldTypeString},
+			Values: []table.Value{value.NewStringValue("Mike"), value.NewStringValue("Anna")},
+		},
+	})
+	olderThan20 := DummyValueOperation{
+		CompareOperation: table.CompareValueOperation{
+			ColumnName: "age",
+			Type:       table.CompareOperationTypeMore,
+			Val:        20.0,
+		},
+	}
+	namedAnna := DummyValueOperation{
+		CompareOperation: table.CompareValueOperation{
+			ColumnName: "name",
+			Type:       table.CompareOperationTypeEqual,
+			Val:        "Anna",
+		},
+	}
+
+	tests := []struct {
+		name string
+		op   table.LogicalOperation
+		want []int
+	}{
+		{
+			name: "unknown comparison is not negated",
+			op:   NotOperation{Operation: olderThan20},
+			want: []int{0},
+		},
+		{
+			name: "unknown or true",
+			op:   NotOperation{Operation: OrOperation{Left: olderThan20, Right: namedAnna}},
+			want: []int{0},
+		},
+		{
+			name: "unknown and false",
+			op:   NotOperation{Operation: AndOperation{Left: olderThan20, Right: namedAnna}},
+			want: []int{0},
+		},
+		{
+			name: "is not null",
+			op:   NotOperation{Operation: IsNullOperation{ColumnName: "age"}},
+			want: []int{0},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Apply(ctx, tbl)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, tt.want, got)
+
+			for rowIndex := 0; rowIndex < tbl.RowCount(); rowIndex++ {
+				match, err := tt.op.Match(tbl, rowIndex)
+				assert.ErrorIs(t, err, nil)
+				assert.Equal(t, contains(tt.want, rowIndex), match)
+			}
+		})
+	}
+}
+
+func contains(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestDummyValueOperation_PatternOnNotString(t *testing.T) {
+	tbl := newTestTable()
+	pattern, err := value.CompilePattern("1%", table.CompareOperationTypeLike)
+	assert.NoError(t, err)
+	op := DummyValueOperation{CompareOperation: table.CompareValueOperation{
+		ColumnName: "age",
+		Type:       table.CompareOperationTypeLike,
+		Val:        pattern,
+	}}
+
+	_, err = op.Apply(context.Background(), tbl)
+	assert.EqualError(t, err, "like is only supported for string fields, 'age' is number")
+	_, err = op.Match(tbl, 0)
+	assert.EqualError(t, err, "like is only supported for string fields, 'age' is number")
+}
@@ -0,0 +1,157 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+var _ table.LogicalOperation = InOperation{}
+var _ table.LogicalOperation = InSubqueryOperation{}
+var _ table.LogicalOperation = notInSubqueryOperation{}
+var _ table.LogicalOperation = BetweenOperation{}
+var _ table.LogicalOperation = IsNullOperation{}
+
+// InOperation отбирает строки, значение поля которых совпадает с одним из значений списка
+type InOperation struct {
+	ColumnName string
+	Values     []interface{}
+}
+
+func (o InOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	return applyToColumn(ctx, t, o.ColumnName, o.check)
+}
+
+func (o InOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	return matchColumn(t, o.ColumnName, rowIndex, o.check)
+}
+
+func (o InOperation) check(val table.Value) (bool, error) {
+	for _, v := range o.Values {
+		accept, err := val.Compare(v, table.CompareOperationTypeEqual)
+		if err != nil || accept {
+			return accept, err
+		}
+	}
+
+	return false, nil
+}
+
+// SubqueryResult - значения, которые вернул вложенный запрос. Заполняется при выполнении
+// внешнего запроса до применения условий.
+type SubqueryResult struct {
+	values map[string]struct{}
+	// hasNull - среди значений есть отсутствующее, поэтому результат not in неизвестен
+	hasNull bool
+}
+
+// SetResult запоминает значения единственного поля результата вложенного запроса
+func (r *SubqueryResult) SetResult(t table.Table) error {
+	if len(t.Columns) != 1 {
+		return fmt.Errorf("subquery in in should return one field, got %d", len(t.Columns))
+	}
+	r.values = make(map[string]struct{}, t.RowCount())
+	r.hasNull = false
+	for _, val := range t.Columns[0].Values {
+		key, valid := table.HashKey(val)
+		if !valid {
+			r.hasNull = true
+
+			continue
+		}
+		r.values[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// InSubqueryOperation отбирает строки, значение поля которых есть в результате вложенного запроса
+type InSubqueryOperation struct {
+	ColumnName string
+	Result     *SubqueryResult
+}
+
+func (o InSubqueryOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	return applyToColumn(ctx, t, o.ColumnName, o.check)
+}
+
+func (o InSubqueryOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	return matchColumn(t, o.ColumnName, rowIndex, o.check)
+}
+
+func (o InSubqueryOperation) check(val table.Value) (bool, error) {
+	if o.Result.values == nil {
+		return false, fmt.Errorf("subquery is not executed")
+	}
+	key, valid := table.HashKey(val)
+	if !valid {
+		return false, nil
+	}
+	_, found := o.Result.values[key]
+
+	return found, nil
+}
+
+// notInSubqueryOperation - отрицание InSubqueryOperation. Если вложенный запрос вернул NULL,
+// результат not in для любой строки ложен или неизвестен, поэтому строки не возвращаются.
+type notInSubqueryOperation struct {
+	In InSubqueryOperation
+}
+
+func (o notInSubqueryOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	if o.In.Result.hasNull {
+		return nil, nil
+	}
+
+	return complementOperation{Operation: o.In, ColumnNames: []string{o.In.ColumnName}}.Apply(ctx, t)
+}
+
+func (o notInSubqueryOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	if o.In.Result.hasNull {
+		return false, nil
+	}
+
+	return complementOperation{Operation: o.In, ColumnNames: []string{o.In.ColumnName}}.Match(t, rowIndex)
+}
+
+// BetweenOperation отбирает строки, значение поля которых лежит в отрезке [From, To]
+type BetweenOperation struct {
+	ColumnName string
+	From       interface{}
+	To         interface{}
+}
+
+func (o BetweenOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	return applyToColumn(ctx, t, o.ColumnName, o.check)
+}
+
+func (o BetweenOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	return matchColumn(t, o.ColumnName, rowIndex, o.check)
+}
+
+func (o BetweenOperation) check(val table.Value) (bool, error) {
+	accept, err := val.Compare(o.From, table.CompareOperationTypeMoreOrEqual)
+	if err != nil || !accept {
+		return false, err
+	}
+
+	return val.Compare(o.To, table.CompareOperationTypeLessOrEqual)
+}
+
+// IsNullOperation отбирает строки, в которых значение поля отсутствует
+type IsNullOperation struct {
+	ColumnName string
+}
+
+func (o IsNullOperation) Apply(ctx context.Context, t table.Table) ([]int, error) {
+	return applyToColumn(ctx, t, o.ColumnName, o.check)
+}
+
+func (o IsNullOperation) Match(t table.Table, rowIndex int) (bool, error) {
+	return matchColumn(t, o.ColumnName, rowIndex, o.check)
+}
+
+func (o IsNullOperation) check(val table.Value) (bool, error) {
+	return val.Value() == nil, nil
+}
@@ -0,0 +1,108 @@
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredicateOperation_Apply(t *testing.T) {
+	tests := []struct {
+		name string
+		op   table.LogicalOperation
+		want []int
+	}{
+		{
+			name: "in",
+			op: InOperation{
+				ColumnName: "age",
+				Values:     []interface{}{12.0, 70.0, 100.0},
+			},
+			want: []int{3, 5},
+		},
+		{
+			name: "between includes bounds",
+			op: BetweenOperation{
+				ColumnName: "age",
+				From:       18.0,
+				To:         33.0,
+			},
+			want: []int{0, 1, 4},
+		},
+		{
+			name: "is null",
+			op:   IsNullOperation{ColumnName: "age"},
+			want: nil,
+		},
+	}
+
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Apply(ctx, tbl)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, tt.want, got)
+
+			for _, rowIndex := range tt.want {
+				match, err := tt.op.Match(tbl, rowIndex)
+				assert.ErrorIs(t, err, nil)
+				assert.True(t, match)
+			}
+		})
+	}
+}
+
+func TestInSubqueryOperation_Apply(t *testing.T) {
+	result := &SubqueryResult{}
+	op := InSubqueryOperation{ColumnName: "age", Result: result}
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	_, err := op.Apply(ctx, tbl)
+	assert.EqualError(t, err, "subquery is not executed")
+
+	values := make([]table.Value, 0, 3)
+	for _, age := range []string{"12.0", "70", "100"} {
+		val, _ := value.NewNumberValue(age)
+		values = append(values, val)
+	}
+	values = append(values, value.NewNullValue())
+	sub := table.NewTable("sub", []table.Column{
+		{
+			Field:  table.Field{Name: "age", Type: table.FieldTypeNumber, Nullable: true},
+			Values: values,
+		},
+	})
+	assert.ErrorIs(t, result.SetResult(sub), nil)
+
+	got, err := op.Apply(ctx, tbl)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, []int{3, 5}, got)
+
+	// NULL в результате вложенного запроса делает результат not in неизвестным
+	got, err = NotOperation{Operation: op}.Apply(ctx, tbl)
+	assert.ErrorIs(t, err, nil)
+	assert.Empty(t, got)
+	accept, err := NotOperation{Operation: op}.Match(tbl, 0)
+	assert.ErrorIs(t, err, nil)
+	assert.False(t, accept)
+
+	withoutNull := table.NewTable("sub", []table.Column{
+		{Field: sub.Columns[0].Field, Values: values[:3]},
+	})
+	assert.ErrorIs(t, result.SetResult(withoutNull), nil)
+	got, err = NotOperation{Operation: op}.Apply(ctx, tbl)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, []int{0, 1, 2, 4}, got)
+	accept, err = NotOperation{Operation: op}.Match(tbl, 0)
+	assert.ErrorIs(t, err, nil)
+	assert.True(t, accept)
+
+	twoFields := table.NewTable("sub", []table.Column{sub.Columns[0], sub.Columns[0]})
+	assert.EqualError(t, result.SetResult(twoFields), "subquery in in should return one field, got 2")
+}
@@ -0,0 +1,10 @@
+package table
+
+type SetOperationType string
+
+const (
+	SetOperationTypeUnion     SetOperationType = "union"
+	SetOperationTypeUnionAll  SetOperationType = "union all"
+	SetOperationTypeIntersect SetOperationType = "intersect"
+	SetOperationTypeExcept    SetOperationType = "except"
+)
@@ -0,0 +1,150 @@
+package set
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+// Combine объединяет строки таблиц left и right. Поля результата называются так же, как поля left.
+// Кроме SetOperationTypeUnionAll, в результат попадают только различающиеся строки,
+// при этом пустые значения считаются равными друг другу.
+func Combine(ctx context.Context, left, right table.Table, opType table.SetOperationType) (table.Table, error) {
+	if len(left.Columns) != len(right.Columns) {
+		return table.Table{}, fmt.Errorf("%s requires the same number of fields: %d and %d",
+			opType, len(left.Columns), len(right.Columns))
+	}
+
+	cols := make([]table.Column, 0, len(left.Columns))
+	for i, col := range left.Columns {
+		rightField := right.Columns[i].Field
+		if col.Field.Type != rightField.Type {
+			return table.Table{}, fmt.Errorf("cannot %s %s field %s with %s field %s",
+				opType, col.Field.Type, col.Field.Name, rightField.Type, rightField.Name)
+		}
+		field := col.Field
+		field.Nullable = field.Nullable || rightField.Nullable
+		// Значения decimal приводятся к большему из масштабов, чтобы не терять знаки после запятой
+		if rightField.Scale > field.Scale {
+			field.Scale = rightField.Scale
+		}
+		cols = append(cols, table.Column{Field: field})
+	}
+
+	appendRow := func(t table.Table, rowIndex int) error {
+		for i, col := range t.Columns {
+			val, err := rescale(col.Values[rowIndex], cols[i].Field)
+			if err != nil {
+				return err
+			}
+			cols[i].Values = append(cols[i].Values, val)
+		}
+
+		return nil
+	}
+
+	var rightKeys map[string]struct{}
+	if opType == table.SetOperationTypeIntersect || opType == table.SetOperationTypeExcept {
+		rightKeys = make(map[string]struct{}, right.RowCount())
+		for rowIndex := 0; rowIndex < right.RowCount(); rowIndex++ {
+			select {
+			case <-ctx.Done():
+				return table.Table{}, ctx.Err()
+			default:
+			}
+			rightKeys[rowKey(right, rowIndex)] = struct{}{}
+		}
+	}
+
+	seen := make(map[string]struct{}, left.RowCount())
+	for rowIndex := 0; rowIndex < left.RowCount(); rowIndex++ {
+		select {
+		case <-ctx.Done():
+			return table.Table{}, ctx.Err()
+		default:
+		}
+		if opType == table.SetOperationTypeUnionAll {
+			if err := appendRow(left, rowIndex); err != nil {
+				return table.Table{}, err
+			}
+			continue
+		}
+
+		key := rowKey(left, rowIndex)
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		_, inRight := rightKeys[key]
+		if opType == table.SetOperationTypeIntersect && !inRight || opType == table.SetOperationTypeExcept && inRight {
+			continue
+		}
+		if err := appendRow(left, rowIndex); err != nil {
+			return table.Table{}, err
+		}
+	}
+
+	if opType == table.SetOperationTypeUnion || opType == table.SetOperationTypeUnionAll {
+		for rowIndex := 0; rowIndex < right.RowCount(); rowIndex++ {
+			select {
+			case <-ctx.Done():
+				return table.Table{}, ctx.Err()
+			default:
+			}
+			if opType == table.SetOperationTypeUnion {
+				key := rowKey(right, rowIndex)
+				if _, found := seen[key]; found {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			if err := appendRow(right, rowIndex); err != nil {
+				return table.Table{}, err
+			}
+		}
+	}
+
+	return table.NewTable(left.Name, cols), nil
+}
+
+// Distinct возвращает строки таблицы без повторов в порядке их первого появления
+func Distinct(ctx context.Context, t table.Table) (table.Table, error) {
+	seen := make(map[string]struct{}, t.RowCount())
+	indexes := make([]int, 0, t.RowCount())
+	for rowIndex := 0; rowIndex < t.RowCount(); rowIndex++ {
+		select {
+		case <-ctx.Done():
+			return table.Table{}, ctx.Err()
+		default:
+		}
+		key := rowKey(t, rowIndex)
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = struct{}{}
+		indexes = append(indexes, rowIndex)
+	}
+
+	return t.GetSubTableByIndexes(ctx, indexes)
+}
+
+// rescale приводит значение decimal к масштабу поля field
+func rescale(val table.Value, field table.Field) (table.Value, error) {
+	dec, isDecimal := val.(value.DecimalValue)
+	if !isDecimal || dec.Scale() == field.Scale {
+		return val, nil
+	}
+
+	return value.NewDecimalValueFromRat(dec.Value().(*big.Rat), field.Scale)
+}
+
+// rowKey собирает ключ строки из значений всех полей
+func rowKey(t table.Table, rowIndex int) string {
+	key, _ := table.RowKey(t.Columns, rowIndex)
+
+	return key
+}
@@ -0,0 +1,133 @@
+package set
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExport(name string, regions []string, units []int64) table.Table {
+	regionValues := make([]table.Value, 0, len(regions))
+	for _, region := range regions {
+		if region == "" {
+			regionValues = append(regionValues, value.NewNullValue())
+			continue
+		}
+		regionValues = append(regionValues, value.NewStringValue(region))
+	}
+	unitValues := make([]table.Value, 0, len(units))
+	for _, u := range units {
+		unitValues = append(unitValues, value.NewIntValueFromInt64(u))
+	}
+
+	return table.NewTable(name, []table.Column{
+		{Field: table.Field{Name: "region", Type: table.FieldTypeString, Nullable: true}, Values: regionValues},
+		{Field: table.Field{Name: "units", Type: table.FieldTypeInt}, Values: unitValues},
+	})
+}
+
+func TestCombine(t *testing.T) {
+	march := newExport("march", []string{"Europe", "Asia", "Europe", ""}, []int64{10, 5, 10, 1})
+	april := newExport("april", []string{"Asia", "", "Africa"}, []int64{5, 1, 7})
+
+	tests := []struct {
+		name   string
+		opType table.SetOperationType
+		want   [][]string
+	}{
+		{
+			name:   "union",
+			opType: table.SetOperationTypeUnion,
+			want:   [][]string{{"Europe", "10"}, {"Asia", "5"}, {"NULL", "1"}, {"Africa", "7"}},
+		},
+		{
+			name:   "union all",
+			opType: table.SetOperationTypeUnionAll,
+			want: [][]string{
+				{"Europe", "10"}, {"Asia", "5"}, {"Europe", "10"}, {"NULL", "1"},
+				{"Asia", "5"}, {"NULL", "1"}, {"Africa", "7"},
+			},
+		},
+		{
+			name:   "intersect",
+			opType: table.SetOperationTypeIntersect,
+			want:   [][]string{{"Asia", "5"}, {"NULL", "1"}},
+		},
+		{
+			name:   "except",
+			opType: table.SetOperationTypeExcept,
+			want:   [][]string{{"Europe", "10"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Combine(context.Background(), march, april, tt.opType)
+			assert.NoError(t, err)
+
+			rows := make([][]string, 0, got.RowCount())
+			for _, rowIndex := range got.RowIndexes() {
+				row := make([]string, 0, len(got.Columns))
+				for _, col := range got.Columns {
+					row = append(row, col.Values[rowIndex].String())
+				}
+				rows = append(rows, row)
+			}
+			assert.Equal(t, tt.want, rows)
+			assert.Equal(t, "march", got.Name)
+		})
+	}
+
+	regions, err := march.GetSubTableByFields([]string{"region"})
+	assert.NoError(t, err)
+	_, err = Combine(context.Background(), march, regions, table.SetOperationTypeExcept)
+	assert.EqualError(t, err, "except requires the same number of fields: 2 and 1")
+
+	swapped, err := april.GetSubTableByFields([]string{"units", "region"})
+	assert.NoError(t, err)
+	_, err = Combine(context.Background(), march, swapped, table.SetOperationTypeUnion)
+	assert.EqualError(t, err, "cannot union string field region with int field units")
+}
+
+func TestDistinct(t *testing.T) {
+	march := newExport("march", []string{"Europe", "", "Europe", "", "Europe"}, []int64{10, 1, 10, 1, 3})
+
+	got, err := Distinct(context.Background(), march)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, got.RowIndexes())
+
+	rows := make([]string, 0, got.RowCount())
+	for _, rowIndex := range got.RowIndexes() {
+		rows = append(rows, got.Columns[0].Values[rowIndex].String()+" "+got.Columns[1].Values[rowIndex].String())
+	}
+	assert.Equal(t, []string{"Europe 10", "NULL 1", "Europe 3"}, rows)
+}
+
+func TestCombine_DecimalScale(t *testing.T) {
+	newPrices := func(name string, scale int, units ...int64) table.Table {
+		values := make([]table.Value, 0, len(units))
+		for _, u := range units {
+			values = append(values, value.NewDecimalValueFromUnits(u, scale))
+		}
+
+		return table.NewTable(name, []table.Column{
+			{Field: table.Field{Name: "price", Type: table.FieldTypeDecimal, Scale: scale}, Values: values},
+		})
+	}
+	cents := newPrices("cents", 2, 125, 250)
+	precise := newPrices("precise", 4, 12500, 31250)
+
+	got, err := Combine(context.Background(), cents, precise, table.SetOperationTypeUnion)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, got.Columns[0].Field.Scale)
+
+	prices := make([]string, 0, got.RowCount())
+	for _, val := range got.Columns[0].Values {
+		assert.Equal(t, 4, val.(value.DecimalValue).Scale())
+		prices = append(prices, val.String())
+	}
+	assert.Equal(t, []string{"1.2500", "2.5000", "3.1250"}, prices)
+}
@@ -3,15 +3,69 @@ package table
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type CompareOperationType string
 
 const (
-	CompareOperationTypeEqual CompareOperationType = "="
-	CompareOperationTypeLess  CompareOperationType = "<"
-	CompareOperationTypeMore  CompareOperationType = ">"
-	CompareOperationTypeDummy CompareOperationType = "dummy"
+	CompareOperationTypeEqual       CompareOperationType = "="
+	CompareOperationTypeNotEqual    CompareOperationType = "!="
+	CompareOperationTypeLess        CompareOperationType = "<"
+	CompareOperationTypeLessOrEqual CompareOperationType = "<="
+	CompareOperationTypeMore        CompareOperationType = ">"
+	CompareOperationTypeMoreOrEqual CompareOperationType = ">="
+	CompareOperationTypeLike        CompareOperationType = "like"
+	CompareOperationTypeILike       CompareOperationType = "ilike"
+	CompareOperationTypeRegexp      CompareOperationType = "regexp"
+	CompareOperationTypeDummy       CompareOperationType = "dummy"
+)
+
+// Mirror возвращает операцию, которая даёт тот же результат при перестановке операндов
+func (t CompareOperationType) Mirror() CompareOperationType {
+	switch t {
+	case CompareOperationTypeLess:
+		return CompareOperationTypeMore
+	case CompareOperationTypeMore:
+		return CompareOperationTypeLess
+	case CompareOperationTypeLessOrEqual:
+		return CompareOperationTypeMoreOrEqual
+	case CompareOperationTypeMoreOrEqual:
+		return CompareOperationTypeLessOrEqual
+	}
+
+	return t
+}
+
+type AggregateType string
+
+const (
+	AggregateTypeCount AggregateType = "count"
+	AggregateTypeSum   AggregateType = "sum"
+	AggregateTypeAvg   AggregateType = "avg"
+	AggregateTypeMin   AggregateType = "min"
+	AggregateTypeMax   AggregateType = "max"
+)
+
+type ArithmeticOperationType string
+
+const (
+	ArithmeticOperationTypePlus     ArithmeticOperationType = "+"
+	ArithmeticOperationTypeMinus    ArithmeticOperationType = "-"
+	ArithmeticOperationTypeMultiply ArithmeticOperationType = "*"
+	ArithmeticOperationTypeDivide   ArithmeticOperationType = "/"
+)
+
+type LogicalOperationType string
+
+const (
+	LogicalOperationTypeAnd LogicalOperationType = "and"
+	LogicalOperationTypeOr  LogicalOperationType = "or"
 )
 
 type Formatter interface {
@@ -28,23 +82,92 @@ type FieldType int
 
 type LogicalOperation interface {
 	Apply(ctx context.Context, t Table) ([]int, error)
+	Match(t Table, rowIndex int) (bool, error)
 }
 
 const (
-	FieldTypeNumber FieldType = iota
-	FieldTypeString FieldType = iota
+	FieldTypeNumber   FieldType = iota
+	FieldTypeString   FieldType = iota
+	FieldTypeDate     FieldType = iota
+	FieldTypeDatetime FieldType = iota
+	FieldTypeBool     FieldType = iota
+	FieldTypeInt      FieldType = iota
+	FieldTypeDecimal  FieldType = iota
 )
 
+// IsNumeric сообщает, является ли тип числовым: значения числовых типов сравниваются между собой
+func (t FieldType) IsNumeric() bool {
+	return t == FieldTypeNumber || t == FieldTypeInt || t == FieldTypeDecimal
+}
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldTypeNumber:
+		return "number"
+	case FieldTypeString:
+		return "string"
+	case FieldTypeDate:
+		return "date"
+	case FieldTypeDatetime:
+		return "datetime"
+	case FieldTypeBool:
+		return "bool"
+	case FieldTypeInt:
+		return "int"
+	case FieldTypeDecimal:
+		return "decimal"
+	}
+
+	return "unknown"
+}
+
 type Field struct {
 	Name string
-	Type FieldType
+	// Table - псевдоним таблицы запроса, из которой взято поле. К такому полю можно обратиться
+	// по наименованию '<псевдоним>.<поле>', а без псевдонима - если наименование не повторяется.
+	Table    string
+	Type     FieldType
+	Nullable bool
+	// Layout - формат даты и времени в нотации пакета time для полей date и datetime
+	Layout string
+	// Scale - число знаков после запятой для полей decimal
+	Scale int
+}
+
+// Expression вычисляет значение по строке таблицы
+type Expression interface {
+	fmt.Stringer
+	// Field возвращает описание поля с результатом выражения без наименования
+	Field(t Table) (Field, error)
+	Evaluate(t Table, rowIndex int) (Value, error)
 }
 
 type Value interface {
 	fmt.Stringer
+	Value() interface{}
 	Compare(val interface{}, op CompareOperationType) (bool, error)
 }
 
+type Aggregate struct {
+	Type       AggregateType
+	ColumnName string
+	// Distinct - повторяющиеся значения поля учитываются один раз
+	Distinct bool
+}
+
+func (a Aggregate) Name() string {
+	if a.Distinct {
+		return fmt.Sprintf("%s(distinct %s)", a.Type, a.ColumnName)
+	}
+
+	return fmt.Sprintf("%s(%s)", a.Type, a.ColumnName)
+}
+
+type OrderField struct {
+	Name string
+	Desc bool
+}
+
 type Column struct {
 	Field  Field
 	Values []Value
@@ -53,7 +176,21 @@ type Column struct {
 func NewTable(name string, cols []Column) Table {
 	columnIndexes := make(map[string]int)
 	for i, col := range cols {
-		columnIndexes[col.Field.Name] = i
+		if col.Field.Table == "" {
+			columnIndexes[col.Field.Name] = i
+		}
+	}
+	// Поля соединённых таблиц доступны с псевдонимом, а без него - только если наименование не повторяется
+	for i, col := range cols {
+		if col.Field.Table == "" {
+			continue
+		}
+		columnIndexes[col.Field.Table+"."+col.Field.Name] = i
+		if _, found := columnIndexes[col.Field.Name]; found {
+			columnIndexes[col.Field.Name] = ambiguousColumn
+		} else {
+			columnIndexes[col.Field.Name] = i
+		}
 	}
 
 	return Table{
@@ -67,6 +204,15 @@ type Table struct {
 	Name          string
 	columnIndexes map[string]int
 	Columns       []Column
+	// Source заполняется для таблиц, загруженных из csv-файла
+	Source *Source
+}
+
+// Source описывает формат csv-файла, из которого загружена таблица
+type Source struct {
+	Sep        rune
+	LazyQuotes bool
+	NullValues []string
 }
 
 func (t Table) GetColumnByName(name string) (Column, error) {
@@ -74,10 +220,30 @@ func (t Table) GetColumnByName(name string) (Column, error) {
 	if !found {
 		return Column{}, fmt.Errorf("column '%s' not found", name)
 	}
+	if i == ambiguousColumn {
+		return Column{}, fmt.Errorf("column '%s' is ambiguous", name)
+	}
 
 	return t.Columns[i], nil
 }
 
+func (t Table) RowCount() int {
+	if len(t.Columns) == 0 {
+		return 0
+	}
+
+	return len(t.Columns[0].Values)
+}
+
+func (t Table) RowIndexes() []int {
+	ret := make([]int, t.RowCount())
+	for i := range ret {
+		ret[i] = i
+	}
+
+	return ret
+}
+
 func (t Table) GetSubTableByIndexes(ctx context.Context, rowIndexes []int) (Table, error) {
 	cols := make([]Column, len(t.Columns))
 
@@ -98,30 +264,235 @@ func (t Table) GetSubTableByIndexes(ctx context.Context, rowIndexes []int) (Tabl
 	return NewTable(t.Name, cols), nil
 }
 
+// GetSubTableByFields возвращает таблицу из полей fields в указанном порядке.
+// Поле может быть указано несколько раз, '*' и '<таблица>.*' заменяются всеми полями таблицы.
 func (t Table) GetSubTableByFields(fields []string) (Table, error) {
-	fieldMap := make(map[string]struct{})
+	cols := make([]Column, 0, len(fields))
+	var notFound []string
+
 	for _, f := range fields {
-		fieldMap[f] = struct{}{}
+		names := []string{f}
+		if isAllFields(f) {
+			names = t.getFieldNamesByPrefix(strings.TrimSuffix(f, "*"))
+		}
+		if len(names) == 0 {
+			notFound = append(notFound, f)
+		}
+		for _, name := range names {
+			col, err := t.GetColumnByName(name)
+			if err != nil && t.columnIndexes[name] == ambiguousColumn {
+				return Table{}, err
+			}
+			if err != nil {
+				notFound = append(notFound, name)
+
+				continue
+			}
+			// Поле результата называется так, как оно указано в запросе
+			col.Field.Name, col.Field.Table = name, ""
+			cols = append(cols, col)
+		}
+	}
+	if len(notFound) != 0 {
+		return Table{}, fmt.Errorf("fields %s not found", strings.Join(notFound, ", "))
 	}
 
-	cols := make([]Column, 0, len(fieldMap))
+	return NewTable(t.Name, cols), nil
+}
 
-	for i, f := range t.Columns {
-		if _, found := fieldMap[f.Field.Name]; found {
-			cols = append(cols, t.Columns[i])
-			delete(fieldMap, f.Field.Name)
+// ExpandFields заменяет '*' и '<таблица>.*' наименованиями полей таблицы.
+// Остальные поля и шаблоны, которым не соответствует ни одно поле, возвращаются без изменений.
+func (t Table) ExpandFields(fields []string) []string {
+	ret := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names := []string{f}
+		if isAllFields(f) {
+			if found := t.getFieldNamesByPrefix(strings.TrimSuffix(f, "*")); len(found) > 0 {
+				names = found
+			}
 		}
+		ret = append(ret, names...)
 	}
-	if len(fieldMap) != 0 {
-		notFoundField := ""
-		for k := range fieldMap {
-			notFoundField = k
 
-			break
+	return ret
+}
+
+func isAllFields(name string) bool {
+	return name == "*" || strings.HasSuffix(name, ".*")
+}
+
+// getFieldNamesByPrefix возвращает наименования полей, начинающиеся с prefix.
+// Префикс из наименования самой таблицы или псевдонима соединённой таблицы соответствует всем её полям.
+func (t Table) getFieldNamesByPrefix(prefix string) []string {
+	var ret []string
+	for i, col := range t.Columns {
+		switch {
+		case col.Field.Table != "" && prefix == col.Field.Table+".",
+			prefix == "",
+			col.Field.Table == "" && prefix == t.Name+".",
+			strings.HasPrefix(col.Field.Name, prefix):
+			ret = append(ret, t.fieldName(i))
 		}
+	}
+
+	return ret
+}
 
-		return Table{}, fmt.Errorf("fields %s not found", notFoundField)
+// fieldName возвращает наименование, по которому можно обратиться к полю:
+// с псевдонимом таблицы, только если без него наименование неоднозначно
+func (t Table) fieldName(i int) string {
+	field := t.Columns[i].Field
+	if field.Table != "" && t.columnIndexes[field.Name] != i {
+		return field.Table + "." + field.Name
 	}
 
-	return NewTable(t.Name, cols), nil
+	return field.Name
+}
+
+// SortRowIndexes возвращает индексы строк, упорядоченные по значениям полей
+func (t Table) SortRowIndexes(ctx context.Context, rowIndexes []int, fields []OrderField) ([]int, error) {
+	cols := make([]Column, 0, len(fields))
+	for _, f := range fields {
+		col, err := t.GetColumnByName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+
+	ret := make([]int, len(rowIndexes))
+	copy(ret, rowIndexes)
+
+	var sortErr error
+	sort.SliceStable(ret, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			sortErr = ctx.Err()
+
+			return false
+		default:
+		}
+
+		for k, col := range cols {
+			res, err := CompareValues(col.Values[ret[i]], col.Values[ret[j]])
+			if err != nil {
+				sortErr = err
+
+				return false
+			}
+			if res != 0 {
+				return (res < 0) != fields[k].Desc
+			}
+		}
+
+		return false
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	return ret, nil
+}
+
+// CompareValues возвращает -1, 0 или 1 в зависимости от порядка значений.
+// Отсутствующее значение меньше любого другого.
+func CompareValues(left, right Value) (int, error) {
+	switch {
+	case left.Value() == nil && right.Value() == nil:
+		return 0, nil
+	case left.Value() == nil:
+		return -1, nil
+	case right.Value() == nil:
+		return 1, nil
+	}
+
+	switch l := left.Value().(type) {
+	case float64:
+		r, valid := right.Value().(float64)
+		if !valid {
+			return compareExact(left, right)
+		}
+		switch {
+		case l < r:
+			return -1, nil
+		case l > r:
+			return 1, nil
+		}
+
+		return 0, nil
+	case int64, *big.Rat:
+		return compareExact(left, right)
+	case string:
+		r, valid := right.Value().(string)
+		if !valid {
+			return 0, fmt.Errorf("cannot compare string with '%v'", right)
+		}
+
+		return strings.Compare(l, r), nil
+	case time.Time:
+		r, valid := right.Value().(time.Time)
+		if !valid {
+			return 0, fmt.Errorf("cannot compare time with '%v'", right)
+		}
+
+		switch {
+		case l.Before(r):
+			return -1, nil
+		case l.After(r):
+			return 1, nil
+		}
+
+		return 0, nil
+	case bool:
+		r, valid := right.Value().(bool)
+		if !valid {
+			return 0, fmt.Errorf("cannot compare bool with '%v'", right)
+		}
+		switch {
+		case l == r:
+			return 0, nil
+		case r:
+			return -1, nil
+		}
+
+		return 1, nil
+	}
+
+	return 0, fmt.Errorf("unknown value type: %T", left.Value())
+}
+
+// compareExact сравнивает числа разных типов, приводя их к точным дробям
+func compareExact(left, right Value) (int, error) {
+	l, valid := ToRat(left.Value())
+	if !valid {
+		return 0, fmt.Errorf("cannot compare '%v' with number", left)
+	}
+	r, valid := ToRat(right.Value())
+	if !valid {
+		return 0, fmt.Errorf("cannot compare number with '%v'", right)
+	}
+
+	return l.Cmp(r), nil
+}
+
+// ToRat приводит числовое значение к точной дроби. Значение number переводится в кратчайшую
+// десятичную запись, чтобы 0.1 совпадало с десятичным 0.1, а не с ближайшей к нему двоичной дробью.
+func ToRat(val interface{}) (*big.Rat, bool) {
+	switch v := val.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+
+		return new(big.Rat).SetString(strconv.FormatFloat(v, 'f', -1, 64))
+	case *big.Rat:
+		return v, true
+	}
+
+	return nil, false
 }
@@ -0,0 +1,130 @@
+package table
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSubTableByFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "order of query",
+			fields: []string{"salary", "lastname"},
+			want:   []string{"salary", "lastname"},
+		},
+		{
+			name:   "repeated fields",
+			fields: []string{"salary", "salary"},
+			want:   []string{"salary", "salary"},
+		},
+		{
+			name:   "asterisk with fields",
+			fields: []string{"id", "*"},
+			want:   []string{"id", "id", "lastname", "salary"},
+		},
+		{
+			name:   "table asterisk",
+			fields: []string{"employees.*", "salary"},
+			want:   []string{"id", "lastname", "salary", "salary"},
+		},
+		{
+			name:    "all missing fields",
+			fields:  []string{"age", "salary", "other.*", "city"},
+			wantErr: "fields age, other.*, city not found",
+		},
+	}
+
+	tbl := NewTable("employees", []Column{
+		{Field: Field{Name: "id", Type: FieldTypeInt}},
+		{Field: Field{Name: "lastname", Type: FieldTypeString}},
+		{Field: Field{Name: "salary", Type: FieldTypeNumber}},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tbl.GetSubTableByFields(tt.fields)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+
+				return
+			}
+			assert.NoError(t, err)
+
+			names := make([]string, 0, len(got.Columns))
+			for _, col := range got.Columns {
+				names = append(names, col.Field.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestQualifiedFields(t *testing.T) {
+	tbl := NewTable("sales", []Column{
+		{Field: Field{Name: "region", Type: FieldTypeString, Table: "s"}},
+		{Field: Field{Name: "units", Type: FieldTypeInt, Table: "s"}},
+		{Field: Field{Name: "region", Type: FieldTypeString, Table: "r"}},
+		{Field: Field{Name: "manager", Type: FieldTypeString, Table: "r"}},
+	})
+
+	assert.Equal(t, []string{"s.region", "units", "r.region", "manager"}, tbl.ExpandFields([]string{"*"}))
+	assert.Equal(t, []string{"r.region", "manager"}, tbl.ExpandFields([]string{"r.*"}))
+
+	sub, err := tbl.GetSubTableByFields([]string{"s.units", "manager", "r.region"})
+	assert.NoError(t, err)
+	names := make([]string, 0, len(sub.Columns))
+	for _, col := range sub.Columns {
+		names = append(names, col.Field.Name)
+	}
+	assert.Equal(t, []string{"s.units", "manager", "r.region"}, names)
+
+	_, err = tbl.GetSubTableByFields([]string{"region"})
+	assert.EqualError(t, err, "column 'region' is ambiguous")
+}
+
+// timeValue - значение даты для тестов пакета, которому недоступен пакет value
+type timeValue time.Time
+
+func (v timeValue) String() string {
+	return time.Time(v).String()
+}
+
+func (v timeValue) Value() interface{} {
+	return time.Time(v)
+}
+
+func (v timeValue) Compare(interface{}, CompareOperationType) (bool, error) {
+	return false, nil
+}
+
+func TestHashKey_Time(t *testing.T) {
+	dates := []time.Time{
+		time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		// Отстоит от 1970-01-01 ровно на 2^64 наносекунд
+		time.Unix(18446744073, 709551616).UTC(),
+	}
+
+	keys := make(map[string]struct{}, len(dates))
+	for _, date := range dates {
+		key, valid := HashKey(timeValue(date))
+		assert.True(t, valid)
+		keys[key] = struct{}{}
+	}
+	assert.Len(t, keys, len(dates))
+
+	// Одинаковый момент времени в разных часовых поясах даёт одинаковый ключ
+	moscow := time.FixedZone("MSK", 3*60*60)
+	utcKey, _ := HashKey(timeValue(dates[1]))
+	localKey, _ := HashKey(timeValue(dates[1].In(moscow)))
+	assert.Equal(t, utcKey, localKey)
+}
@@ -0,0 +1,60 @@
+package value
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+var _ table.Value = BoolValue{}
+
+func NewBoolValue(val string) (BoolValue, error) {
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return BoolValue{}, err
+	}
+
+	return BoolValue{value: b}, nil
+}
+
+func NewBoolValueFromBool(val bool) BoolValue {
+	return BoolValue{value: val}
+}
+
+type BoolValue struct {
+	value bool
+}
+
+func (v BoolValue) String() string {
+	return strconv.FormatBool(v.value)
+}
+
+func (v BoolValue) Value() interface{} {
+	return v.value
+}
+
+func (v BoolValue) Compare(val interface{}, op table.CompareOperationType) (bool, error) {
+	var compareValue bool
+	switch b := val.(type) {
+	case bool:
+		compareValue = b
+	case string:
+		parsed, err := strconv.ParseBool(b)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for bool: '%v'", val)
+		}
+		compareValue = parsed
+	default:
+		return false, fmt.Errorf("invalid value for bool: '%v'", val)
+	}
+
+	switch op {
+	case table.CompareOperationTypeEqual:
+		return v.value == compareValue, nil
+	case table.CompareOperationTypeNotEqual:
+		return v.value != compareValue, nil
+	}
+
+	return false, fmt.Errorf("invalid operation for type bool: %s", op)
+}
@@ -0,0 +1,64 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolValue_Compare(t *testing.T) {
+	tests := []struct {
+		name    string
+		val1    string
+		op      table.CompareOperationType
+		val2    interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "bool is equal",
+			val1: "TRUE",
+			op:   table.CompareOperationTypeEqual,
+			val2: true,
+			want: true,
+		},
+		{
+			name: "bool is not equal to string",
+			val1: "0",
+			op:   table.CompareOperationTypeNotEqual,
+			val2: "true",
+			want: true,
+		},
+		{
+			name:    "invalid value",
+			val1:    "false",
+			op:      table.CompareOperationTypeEqual,
+			val2:    "yes",
+			wantErr: true,
+		},
+		{
+			name:    "ordering operation",
+			val1:    "false",
+			op:      table.CompareOperationTypeLess,
+			val2:    true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := NewBoolValue(tt.val1)
+			assert.NoError(t, err)
+
+			got, err := val.Compare(tt.val2, tt.op)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package value
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+var _ table.Value = DecimalValue{}
+
+// MaxDecimalScale - наибольшее число знаков после запятой, при котором 10^scale помещается в int64
+const MaxDecimalScale = 18
+
+func NewDecimalValue(val string, scale int) (DecimalValue, error) {
+	r, valid := new(big.Rat).SetString(val)
+	if !valid {
+		return DecimalValue{}, fmt.Errorf("invalid decimal: '%s'", val)
+	}
+
+	return NewDecimalValueFromRat(r, scale)
+}
+
+// NewExactDecimalValue разбирает число так же, как NewDecimalValue, но вместо округления возвращает ошибку,
+// если у числа больше scale знаков после запятой
+func NewExactDecimalValue(val string, scale int) (DecimalValue, error) {
+	ret, err := NewDecimalValue(val, scale)
+	if err != nil {
+		return DecimalValue{}, err
+	}
+	if r, _ := new(big.Rat).SetString(val); r.Cmp(ret.rat()) != 0 {
+		return DecimalValue{}, fmt.Errorf("'%s' has more than %d digits after the decimal point", val, scale)
+	}
+
+	return ret, nil
+}
+
+// NewDecimalValueFromRat округляет значение до scale знаков после запятой, половина округляется от нуля
+func NewDecimalValueFromRat(r *big.Rat, scale int) (DecimalValue, error) {
+	if scale < 0 || scale > MaxDecimalScale {
+		return DecimalValue{}, fmt.Errorf("decimal scale should be from 0 to %d", MaxDecimalScale)
+	}
+
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt64(Pow10(scale)))
+	units, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		units.Add(units, big.NewInt(int64(scaled.Sign())))
+	}
+	if !units.IsInt64() {
+		return DecimalValue{}, fmt.Errorf("decimal '%s' is out of range", r.FloatString(scale))
+	}
+
+	return DecimalValue{units: units.Int64(), scale: scale}, nil
+}
+
+func NewDecimalValueFromUnits(units int64, scale int) DecimalValue {
+	return DecimalValue{units: units, scale: scale}
+}
+
+// DecimalValue - число с фиксированной точкой: units единиц по 10^-scale
+type DecimalValue struct {
+	units int64
+	scale int
+}
+
+func (v DecimalValue) String() string {
+	return v.rat().FloatString(v.scale)
+}
+
+func (v DecimalValue) Value() interface{} {
+	return v.rat()
+}
+
+func (v DecimalValue) Units() int64 {
+	return v.units
+}
+
+func (v DecimalValue) Scale() int {
+	return v.scale
+}
+
+func (v DecimalValue) Compare(val interface{}, op table.CompareOperationType) (bool, error) {
+	compareValue, valid := toRat(val)
+	if !valid {
+		return false, fmt.Errorf("invalid value for decimal: '%v'", val)
+	}
+
+	return compareOrder(v.rat().Cmp(compareValue), op, "decimal")
+}
+
+func (v DecimalValue) rat() *big.Rat {
+	return new(big.Rat).SetFrac64(v.units, Pow10(v.scale))
+}
+
+// Pow10 возвращает 10 в степени n
+func Pow10(n int) int64 {
+	ret := int64(1)
+	for i := 0; i < n; i++ {
+		ret *= 10
+	}
+
+	return ret
+}
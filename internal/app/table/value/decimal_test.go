@@ -0,0 +1,140 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDecimalValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     string
+		scale   int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "pad to scale",
+			val:   "500.5",
+			scale: 2,
+			want:  "500.50",
+		},
+		{
+			name:  "round half away from zero",
+			val:   "1.005",
+			scale: 2,
+			want:  "1.01",
+		},
+		{
+			name:  "round negative half away from zero",
+			val:   "-1.005",
+			scale: 2,
+			want:  "-1.01",
+		},
+		{
+			name:  "zero scale",
+			val:   "12.4",
+			scale: 0,
+			want:  "12",
+		},
+		{
+			name:    "not a number",
+			val:     "abc",
+			scale:   2,
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			val:     "100000000000000000000",
+			scale:   2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDecimalValue(tt.val, tt.scale)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNewExactDecimalValue(t *testing.T) {
+	got, err := NewExactDecimalValue("1.5", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.50", got.String())
+
+	got, err = NewExactDecimalValue("1.2300", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.23", got.String())
+
+	_, err = NewExactDecimalValue("1.005", 2)
+	assert.EqualError(t, err, "'1.005' has more than 2 digits after the decimal point")
+
+	_, err = NewExactDecimalValue("abc", 2)
+	assert.Error(t, err)
+}
+
+func TestDecimalValue_Compare(t *testing.T) {
+	tests := []struct {
+		name    string
+		val1    string
+		op      table.CompareOperationType
+		val2    interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "cents are equal to float literal",
+			val1: "0.30",
+			op:   table.CompareOperationTypeEqual,
+			val2: 0.3,
+			want: true,
+		},
+		{
+			name: "decimal is less than string",
+			val1: "0.10",
+			op:   table.CompareOperationTypeLess,
+			val2: "0.11",
+			want: true,
+		},
+		{
+			name: "decimal is not more than int",
+			val1: "2.00",
+			op:   table.CompareOperationTypeMore,
+			val2: int64(2),
+			want: false,
+		},
+		{
+			name:    "invalid value",
+			val1:    "2.00",
+			op:      table.CompareOperationTypeEqual,
+			val2:    true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := NewDecimalValue(tt.val1, 2)
+			assert.NoError(t, err)
+
+			got, err := val.Compare(tt.val2, tt.op)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package value
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+var _ table.Value = IntValue{}
+
+func NewIntValue(val string) (IntValue, error) {
+	num, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return IntValue{}, err
+	}
+
+	return IntValue{value: num}, nil
+}
+
+func NewIntValueFromInt64(val int64) IntValue {
+	return IntValue{value: val}
+}
+
+type IntValue struct {
+	value int64
+}
+
+func (v IntValue) String() string {
+	return strconv.FormatInt(v.value, 10)
+}
+
+func (v IntValue) Value() interface{} {
+	return v.value
+}
+
+func (v IntValue) Int64() int64 {
+	return v.value
+}
+
+func (v IntValue) Compare(val interface{}, op table.CompareOperationType) (bool, error) {
+	if compareValue, valid := val.(int64); valid {
+		switch {
+		case v.value < compareValue:
+			return compareOrder(-1, op, "int")
+		case v.value > compareValue:
+			return compareOrder(1, op, "int")
+		}
+
+		return compareOrder(0, op, "int")
+	}
+
+	compareValue, valid := toRat(val)
+	if !valid {
+		return false, fmt.Errorf("invalid value for int: '%v'", val)
+	}
+
+	return compareOrder(new(big.Rat).SetInt64(v.value).Cmp(compareValue), op, "int")
+}
@@ -0,0 +1,78 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntValue_Compare(t *testing.T) {
+	tests := []struct {
+		name    string
+		val1    string
+		op      table.CompareOperationType
+		val2    interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "int beyond float precision is equal",
+			val1: "9007199254740993",
+			op:   table.CompareOperationTypeEqual,
+			val2: int64(9007199254740993),
+			want: true,
+		},
+		{
+			name: "int beyond float precision is not equal",
+			val1: "9007199254740993",
+			op:   table.CompareOperationTypeEqual,
+			val2: int64(9007199254740992),
+			want: false,
+		},
+		{
+			name: "int is more than float",
+			val1: "10",
+			op:   table.CompareOperationTypeMore,
+			val2: 9.5,
+			want: true,
+		},
+		{
+			name: "int is less or equal to string",
+			val1: "10",
+			op:   table.CompareOperationTypeLessOrEqual,
+			val2: "10",
+			want: true,
+		},
+		{
+			name:    "invalid value",
+			val1:    "10",
+			op:      table.CompareOperationTypeEqual,
+			val2:    "ten",
+			wantErr: true,
+		},
+		{
+			name:    "pattern operation",
+			val1:    "10",
+			op:      table.CompareOperationTypeLike,
+			val2:    int64(1),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := NewIntValue(tt.val1)
+			assert.NoError(t, err)
+
+			got, err := val.Compare(tt.val2, tt.op)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
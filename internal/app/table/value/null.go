@@ -0,0 +1,30 @@
+package value
+
+import (
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+var _ table.Value = NullValue{}
+
+const nullString = "NULL"
+
+func NewNullValue() NullValue {
+	return NullValue{}
+}
+
+// NullValue - отсутствующее значение поля любого типа
+type NullValue struct{}
+
+func (v NullValue) String() string {
+	return nullString
+}
+
+func (v NullValue) Value() interface{} {
+	return nil
+}
+
+// Compare реализует трёхзначную логику: результат сравнения с отсутствующим значением
+// неизвестен, поэтому строка не проходит ни условие, ни его отрицание
+func (v NullValue) Compare(interface{}, table.CompareOperationType) (bool, error) {
+	return false, nil
+}
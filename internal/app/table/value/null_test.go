@@ -0,0 +1,45 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullValue_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		op   table.CompareOperationType
+		val  interface{}
+	}{
+		{
+			name: "equal",
+			op:   table.CompareOperationTypeEqual,
+			val:  10.0,
+		},
+		{
+			name: "not equal",
+			op:   table.CompareOperationTypeNotEqual,
+			val:  10.0,
+		},
+		{
+			name: "less",
+			op:   table.CompareOperationTypeLess,
+			val:  "abc",
+		},
+		{
+			name: "like",
+			op:   table.CompareOperationTypeLike,
+			val:  "%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewNullValue().Compare(tt.val, tt.op)
+			assert.ErrorIs(t, err, nil)
+			assert.False(t, got)
+		})
+	}
+}
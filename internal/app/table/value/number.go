@@ -2,6 +2,7 @@ package value
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/stepan2volkov/csvdb/internal/app/table"
@@ -18,6 +19,10 @@ func NewNumberValue(val string) (NumberValue, error) {
 	return NumberValue{value: num}, nil
 }
 
+func NewNumberValueFromFloat(val float64) NumberValue {
+	return NumberValue{value: val}
+}
+
 type NumberValue struct {
 	value float64
 }
@@ -26,8 +31,29 @@ func (v NumberValue) String() string {
 	return fmt.Sprint(v.value)
 }
 
+func (v NumberValue) Value() interface{} {
+	return v.value
+}
+
 func (v NumberValue) Compare(val interface{}, op table.CompareOperationType) (bool, error) {
+	switch op {
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike, table.CompareOperationTypeRegexp:
+		return false, fmt.Errorf("unknown operation for type number: %s", op)
+	}
+
 	compareValue, valid := val.(float64)
+	if i, isInt := val.(int64); isInt {
+		compareValue, valid = float64(i), true
+	}
+	if r, isRat := val.(*big.Rat); isRat {
+		// Десятичное значение сравнивается точно, как в table.CompareValues
+		own, ok := table.ToRat(v.value)
+		if !ok {
+			return false, fmt.Errorf("invalid value for number: '%v'", v.value)
+		}
+
+		return compareOrder(own.Cmp(r), op, "number")
+	}
 	if !valid {
 		return false, fmt.Errorf("invalid value for number: '%v'", val)
 	}
@@ -37,8 +63,14 @@ func (v NumberValue) Compare(val interface{}, op table.CompareOperationType) (bo
 		return v.value < compareValue, nil
 	case table.CompareOperationTypeMore:
 		return v.value > compareValue, nil
+	case table.CompareOperationTypeLessOrEqual:
+		return v.value <= compareValue, nil
+	case table.CompareOperationTypeMoreOrEqual:
+		return v.value >= compareValue, nil
 	case table.CompareOperationTypeEqual:
 		return v.value == compareValue, nil
+	case table.CompareOperationTypeNotEqual:
+		return v.value != compareValue, nil
 	}
 
 	return false, fmt.Errorf("unknown operation for type number: %s", op)
@@ -51,6 +51,27 @@ func TestNumberValue_Compare(t *testing.T) {
 			val2: 15.0,
 			want: true,
 		},
+		{
+			name: "number is less or equal",
+			val1: "10",
+			op:   table.CompareOperationTypeLessOrEqual,
+			val2: 10.0,
+			want: true,
+		},
+		{
+			name: "number is not more or equal",
+			val1: "10",
+			op:   table.CompareOperationTypeMoreOrEqual,
+			val2: 10.5,
+			want: false,
+		},
+		{
+			name: "number is not equal",
+			val1: "10",
+			op:   table.CompareOperationTypeNotEqual,
+			val2: 15.0,
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -60,3 +81,14 @@ func TestNumberValue_Compare(t *testing.T) {
 		})
 	}
 }
+
+func TestNumberValue_CompareDecimal(t *testing.T) {
+	n, err := NewNumberValue("0.1")
+	assert.NoError(t, err)
+	d, err := NewDecimalValue("0.10", 2)
+	assert.NoError(t, err)
+
+	equal, err := n.Compare(d.Value(), table.CompareOperationTypeEqual)
+	assert.NoError(t, err)
+	assert.True(t, equal)
+}
@@ -0,0 +1,37 @@
+package value
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+// toRat приводит числовое значение или его строковую запись к точной дроби
+func toRat(val interface{}) (*big.Rat, bool) {
+	if v, isString := val.(string); isString {
+		return new(big.Rat).SetString(v)
+	}
+
+	return table.ToRat(val)
+}
+
+// compareOrder преобразует результат сравнения (-1, 0 или 1) в результат операции op
+func compareOrder(res int, op table.CompareOperationType, typeName string) (bool, error) {
+	switch op {
+	case table.CompareOperationTypeEqual:
+		return res == 0, nil
+	case table.CompareOperationTypeNotEqual:
+		return res != 0, nil
+	case table.CompareOperationTypeLess:
+		return res < 0, nil
+	case table.CompareOperationTypeLessOrEqual:
+		return res <= 0, nil
+	case table.CompareOperationTypeMore:
+		return res > 0, nil
+	case table.CompareOperationTypeMoreOrEqual:
+		return res >= 0, nil
+	}
+
+	return false, fmt.Errorf("invalid operation for type %s: %s", typeName, op)
+}
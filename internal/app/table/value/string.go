@@ -2,6 +2,8 @@ package value
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/stepan2volkov/csvdb/internal/app/table"
 )
@@ -20,15 +22,89 @@ func (v StringValue) String() string {
 	return v.value
 }
 
+func (v StringValue) Value() interface{} {
+	return v.value
+}
+
 func (v StringValue) Compare(val interface{}, op table.CompareOperationType) (bool, error) {
+	switch op {
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike, table.CompareOperationTypeRegexp:
+		return v.match(val, op)
+	}
+
 	compareValue, valid := val.(string)
 	if !valid {
 		return false, fmt.Errorf("invalid value for string: '%v'", val)
 	}
 
-	if op == table.CompareOperationTypeEqual {
+	switch op {
+	case table.CompareOperationTypeEqual:
 		return v.value == compareValue, nil
+	case table.CompareOperationTypeNotEqual:
+		return v.value != compareValue, nil
+	case table.CompareOperationTypeLess:
+		return v.value < compareValue, nil
+	case table.CompareOperationTypeLessOrEqual:
+		return v.value <= compareValue, nil
+	case table.CompareOperationTypeMore:
+		return v.value > compareValue, nil
+	case table.CompareOperationTypeMoreOrEqual:
+		return v.value >= compareValue, nil
 	}
 
 	return false, fmt.Errorf("invalid operation for type string: %s", op)
 }
+
+// match принимает как заранее скомпилированный шаблон, так и строку с шаблоном
+func (v StringValue) match(val interface{}, op table.CompareOperationType) (bool, error) {
+	switch pattern := val.(type) {
+	case *regexp.Regexp:
+		return pattern.MatchString(v.value), nil
+	case string:
+		compiled, err := CompilePattern(pattern, op)
+		if err != nil {
+			return false, err
+		}
+
+		return compiled.MatchString(v.value), nil
+	}
+
+	return false, fmt.Errorf("invalid pattern for string: '%v'", val)
+}
+
+// CompilePattern преобразует шаблон like/ilike в регулярное выражение:
+// '%' соответствует любой последовательности символов, '_' - любому одному символу
+func CompilePattern(pattern string, op table.CompareOperationType) (*regexp.Regexp, error) {
+	switch op {
+	case table.CompareOperationTypeRegexp:
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp '%s': %w", pattern, err)
+		}
+
+		return compiled, nil
+	case table.CompareOperationTypeLike, table.CompareOperationTypeILike:
+	default:
+		return nil, fmt.Errorf("operation %s doesn't use pattern", op)
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^(?s")
+	if op == table.CompareOperationTypeILike {
+		expr.WriteString("i")
+	}
+	expr.WriteString(")")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
@@ -30,6 +30,62 @@ func TestStringValue_Compare(t *testing.T) {
 			val2: "world",
 			want: false,
 		},
+		{
+			name: "string is not equal by operation",
+			val1: "hello",
+			op:   table.CompareOperationTypeNotEqual,
+			val2: "world",
+			want: true,
+		},
+		{
+			name: "string is less",
+			val1: "2020-01-15",
+			op:   table.CompareOperationTypeLess,
+			val2: "2020-02-01",
+			want: true,
+		},
+		{
+			name: "string is not more or equal",
+			val1: "apple",
+			op:   table.CompareOperationTypeMoreOrEqual,
+			val2: "banana",
+			want: false,
+		},
+		{
+			name: "string is like",
+			val1: "South Africa",
+			op:   table.CompareOperationTypeLike,
+			val2: "S%h_Africa",
+			want: true,
+		},
+		{
+			name: "like is case sensitive",
+			val1: "South Africa",
+			op:   table.CompareOperationTypeLike,
+			val2: "south%",
+			want: false,
+		},
+		{
+			name: "string is ilike",
+			val1: "South Africa",
+			op:   table.CompareOperationTypeILike,
+			val2: "south%",
+			want: true,
+		},
+		{
+			name: "like escapes regexp symbols",
+			val1: "a+b",
+			op:   table.CompareOperationTypeLike,
+			val2: "a+_",
+			want: true,
+		},
+		{
+			name: "string matches regexp",
+			val1: "order-1234",
+			op:   table.CompareOperationTypeRegexp,
+			val2: `^order-\d+$`,
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
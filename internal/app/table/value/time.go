@@ -0,0 +1,82 @@
+package value
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+)
+
+var _ table.Value = TimeValue{}
+
+const (
+	DefaultDateLayout     = "2006-01-02"
+	DefaultDatetimeLayout = "2006-01-02 15:04:05"
+)
+
+func NewTimeValue(val string, layout string) (TimeValue, error) {
+	t, err := time.Parse(layout, val)
+	if err != nil {
+		return TimeValue{}, err
+	}
+
+	return TimeValue{value: t, layout: layout}, nil
+}
+
+// TimeValue - значение полей date и datetime, выводится в формате layout
+type TimeValue struct {
+	value  time.Time
+	layout string
+}
+
+func (v TimeValue) String() string {
+	return v.value.Format(v.layout)
+}
+
+func (v TimeValue) Value() interface{} {
+	return v.value
+}
+
+func (v TimeValue) Compare(val interface{}, op table.CompareOperationType) (bool, error) {
+	compareValue, err := v.parse(val)
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case table.CompareOperationTypeEqual:
+		return v.value.Equal(compareValue), nil
+	case table.CompareOperationTypeNotEqual:
+		return !v.value.Equal(compareValue), nil
+	case table.CompareOperationTypeLess:
+		return v.value.Before(compareValue), nil
+	case table.CompareOperationTypeLessOrEqual:
+		return !v.value.After(compareValue), nil
+	case table.CompareOperationTypeMore:
+		return v.value.After(compareValue), nil
+	case table.CompareOperationTypeMoreOrEqual:
+		return !v.value.Before(compareValue), nil
+	}
+
+	return false, fmt.Errorf("invalid operation for type time: %s", op)
+}
+
+// parse принимает время или строку в формате поля либо в одном из форматов по умолчанию
+func (v TimeValue) parse(val interface{}) (time.Time, error) {
+	switch t := val.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		for _, layout := range []string{v.layout, DefaultDatetimeLayout, DefaultDateLayout, time.RFC3339} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid value for time: '%v'", val)
+}
+
+func NewTimeValueFromTime(val time.Time, layout string) TimeValue {
+	return TimeValue{value: val, layout: layout}
+}
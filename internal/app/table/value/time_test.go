@@ -0,0 +1,95 @@
+package value
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeValue_Compare(t *testing.T) {
+	tests := []struct {
+		name    string
+		val1    string
+		layout  string
+		op      table.CompareOperationType
+		val2    interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "date is more",
+			val1:   "2020-03-15",
+			layout: DefaultDateLayout,
+			op:     table.CompareOperationTypeMore,
+			val2:   "2020-01-01",
+			want:   true,
+		},
+		{
+			name:   "date is not less",
+			val1:   "2020-03-15",
+			layout: DefaultDateLayout,
+			op:     table.CompareOperationTypeLess,
+			val2:   "2020-01-01",
+			want:   false,
+		},
+		{
+			name:   "custom layout with default literal",
+			val1:   "01.01.2020 09:30",
+			layout: "02.01.2006 15:04",
+			op:     table.CompareOperationTypeMoreOrEqual,
+			val2:   "2020-01-01",
+			want:   true,
+		},
+		{
+			name:   "custom layout literal",
+			val1:   "01.01.2020 09:30",
+			layout: "02.01.2006 15:04",
+			op:     table.CompareOperationTypeEqual,
+			val2:   "01.01.2020 09:30",
+			want:   true,
+		},
+		{
+			name:   "time value",
+			val1:   "2020-01-01",
+			layout: DefaultDateLayout,
+			op:     table.CompareOperationTypeNotEqual,
+			val2:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:   false,
+		},
+		{
+			name:    "invalid literal",
+			val1:    "2020-01-01",
+			layout:  DefaultDateLayout,
+			op:      table.CompareOperationTypeEqual,
+			val2:    "yesterday",
+			wantErr: true,
+		},
+		{
+			name:    "pattern operation",
+			val1:    "2020-01-01",
+			layout:  DefaultDateLayout,
+			op:      table.CompareOperationTypeLike,
+			val2:    "2020%",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := NewTimeValue(tt.val1, tt.layout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.val1, val.String())
+
+			got, err := val.Compare(tt.val2, tt.op)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+)
+
+type WindowFunctionType string
+
+// Кроме перечисленных функций, в окне можно использовать агрегатные функции: они вычисляются нарастающим итогом
+const (
+	WindowFunctionTypeRowNumber WindowFunctionType = "row_number"
+	WindowFunctionTypeRank      WindowFunctionType = "rank"
+	WindowFunctionTypeLag       WindowFunctionType = "lag"
+	WindowFunctionTypeLead      WindowFunctionType = "lead"
+)
+
+// Window - функция, значение которой для каждой строки вычисляется по строкам её раздела без их объединения.
+// Строки раздела совпадают по значениям полей PartitionBy и упорядочены по полям OrderBy.
+type Window struct {
+	Function   WindowFunctionType
+	ColumnName string
+	// Offset и Default - смещение и значение для строк без пары у функций lag и lead
+	Offset      int
+	Default     Value
+	PartitionBy []string
+	OrderBy     []OrderField
+}
+
+func (w Window) Name() string {
+	args := w.ColumnName
+	if w.Function == WindowFunctionTypeLag || w.Function == WindowFunctionTypeLead {
+		switch {
+		case w.Default != nil:
+			args = fmt.Sprintf("%s, %d, %s", args, w.Offset, w.Default)
+		case w.Offset != 1:
+			args = fmt.Sprintf("%s, %d", args, w.Offset)
+		}
+	}
+
+	over := make([]string, 0, 2)
+	if len(w.PartitionBy) > 0 {
+		over = append(over, "partition by "+strings.Join(w.PartitionBy, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		fields := make([]string, 0, len(w.OrderBy))
+		for _, f := range w.OrderBy {
+			if f.Desc {
+				fields = append(fields, f.Name+" desc")
+
+				continue
+			}
+			fields = append(fields, f.Name)
+		}
+		over = append(over, "order by "+strings.Join(fields, ", "))
+	}
+
+	return fmt.Sprintf("%s(%s) over (%s)", w.Function, args, strings.Join(over, " "))
+}
@@ -0,0 +1,256 @@
+package window
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/aggregate"
+	"github.com/stepan2volkov/csvdb/internal/app/table/expression"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+)
+
+// Apply возвращает строки rowIndexes таблицы с добавленными полями оконных функций.
+// Поле называется так же, как функция: Window.Name().
+func Apply(ctx context.Context, t table.Table, rowIndexes []int, windows []table.Window) (table.Table, error) {
+	sub, err := t.GetSubTableByIndexes(ctx, rowIndexes)
+	if err != nil {
+		return table.Table{}, err
+	}
+
+	cols := sub.Columns
+	for _, w := range windows {
+		col, err := evaluate(ctx, sub, w)
+		if err != nil {
+			return table.Table{}, err
+		}
+		col.Field.Name, col.Field.Table = w.Name(), ""
+		cols = append(cols, col)
+	}
+
+	return table.NewTable(sub.Name, cols), nil
+}
+
+// evaluate вычисляет значения функции по разделам таблицы. Строки раздела, у которых совпадают
+// значения полей сортировки, считаются равными: у них одинаковые rank и нарастающий итог.
+func evaluate(ctx context.Context, t table.Table, w table.Window) (table.Column, error) {
+	partitions, err := partition(ctx, t, w.PartitionBy)
+	if err != nil {
+		return table.Column{}, err
+	}
+	orderCols := make([]table.Column, 0, len(w.OrderBy))
+	for _, f := range w.OrderBy {
+		col, err := t.GetColumnByName(f.Name)
+		if err != nil {
+			return table.Column{}, err
+		}
+		orderCols = append(orderCols, col)
+	}
+
+	fn, err := newFunction(t, w)
+	if err != nil {
+		return table.Column{}, err
+	}
+	values := make([]table.Value, t.RowCount())
+	for _, rowIndexes := range partitions {
+		rowIndexes, err = t.SortRowIndexes(ctx, rowIndexes, w.OrderBy)
+		if err != nil {
+			return table.Column{}, err
+		}
+		peers, err := peerEnds(rowIndexes, orderCols)
+		if err != nil {
+			return table.Column{}, err
+		}
+		if err = fn.apply(ctx, rowIndexes, peers, values); err != nil {
+			return table.Column{}, err
+		}
+	}
+
+	return table.Column{Field: fn.field(), Values: values}, nil
+}
+
+// partition делит строки таблицы на разделы с одинаковыми значениями полей keys
+func partition(ctx context.Context, t table.Table, keys []string) ([][]int, error) {
+	keyCols := make([]table.Column, 0, len(keys))
+	for _, key := range keys {
+		col, err := t.GetColumnByName(key)
+		if err != nil {
+			return nil, err
+		}
+		keyCols = append(keyCols, col)
+	}
+
+	var partitions [][]int
+	indexes := make(map[string]int)
+	for rowIndex := 0; rowIndex < t.RowCount(); rowIndex++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Пустые значения попадают в отдельный раздел
+		key, _ := table.RowKey(keyCols, rowIndex)
+
+		i, found := indexes[key]
+		if !found {
+			partitions = append(partitions, nil)
+			i = len(partitions) - 1
+			indexes[key] = i
+		}
+		partitions[i] = append(partitions[i], rowIndex)
+	}
+
+	return partitions, nil
+}
+
+// peerEnds возвращает для каждой строки упорядоченного раздела позицию, следующую за последней равной ей строкой
+func peerEnds(rowIndexes []int, orderCols []table.Column) ([]int, error) {
+	ends := make([]int, len(rowIndexes))
+	end := len(rowIndexes)
+	for i := len(rowIndexes) - 1; i >= 0; i-- {
+		if i+1 < len(rowIndexes) {
+			equal := true
+			for _, col := range orderCols {
+				res, err := table.CompareValues(col.Values[rowIndexes[i]], col.Values[rowIndexes[i+1]])
+				if err != nil {
+					return nil, err
+				}
+				equal = equal && res == 0
+			}
+			if !equal {
+				end = i + 1
+			}
+		}
+		ends[i] = end
+	}
+
+	return ends, nil
+}
+
+type function interface {
+	// apply записывает в values значения функции для строк упорядоченного раздела rowIndexes
+	apply(ctx context.Context, rowIndexes, peers []int, values []table.Value) error
+	// field возвращает описание поля с результатом функции без наименования
+	field() table.Field
+}
+
+func newFunction(t table.Table, w table.Window) (function, error) {
+	switch w.Function {
+	case table.WindowFunctionTypeRowNumber, table.WindowFunctionTypeRank:
+		return rankFunction{rank: w.Function == table.WindowFunctionTypeRank}, nil
+	case table.WindowFunctionTypeLag, table.WindowFunctionTypeLead:
+		return newOffsetFunction(t, w)
+	}
+
+	a := table.Aggregate{Type: table.AggregateType(w.Function), ColumnName: w.ColumnName}
+	// Описание поля результата не зависит от строк, поэтому проверяем функцию заранее
+	_, field, err := aggregate.Running(context.Background(), t, nil, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return runningFunction{t: t, aggregate: a, resultField: field}, nil
+}
+
+// rankFunction нумерует строки раздела. Для rank равные строки получают одинаковый номер.
+type rankFunction struct {
+	rank bool
+}
+
+func (f rankFunction) apply(_ context.Context, rowIndexes, peers []int, values []table.Value) error {
+	start := 0
+	for i, rowIndex := range rowIndexes {
+		if i > 0 && peers[i] != peers[i-1] {
+			start = i
+		}
+		number := i + 1
+		if f.rank {
+			number = start + 1
+		}
+		values[rowIndex] = value.NewIntValueFromInt64(int64(number))
+	}
+
+	return nil
+}
+
+func (f rankFunction) field() table.Field {
+	return table.Field{Type: table.FieldTypeInt}
+}
+
+// offsetFunction возвращает значение поля строки, которая находится на offset строк раньше (lag)
+// или позже (lead) в разделе
+type offsetFunction struct {
+	col    table.Column
+	offset int
+	def    table.Value
+}
+
+func newOffsetFunction(t table.Table, w table.Window) (offsetFunction, error) {
+	col, err := t.GetColumnByName(w.ColumnName)
+	if err != nil {
+		return offsetFunction{}, err
+	}
+
+	f := offsetFunction{col: col, offset: w.Offset, def: value.NewNullValue()}
+	if w.Function == table.WindowFunctionTypeLag {
+		f.offset = -w.Offset
+	}
+	if w.Default != nil && w.Default.Value() != nil {
+		target := col.Field
+		target.Name, target.Table, target.Nullable = "", "", false
+		cast := expression.CastExpression{Operand: expression.ConstExpression{Value: w.Default}, Target: target}
+		if _, err = cast.Field(t); err != nil {
+			return offsetFunction{}, fmt.Errorf("default of function %s: %w", w.Function, err)
+		}
+		if f.def, err = cast.Evaluate(t, 0); err != nil {
+			return offsetFunction{}, fmt.Errorf("default of function %s: %w", w.Function, err)
+		}
+	}
+
+	return f, nil
+}
+
+func (f offsetFunction) apply(_ context.Context, rowIndexes, _ []int, values []table.Value) error {
+	for i, rowIndex := range rowIndexes {
+		j := i + f.offset
+		if j < 0 || j >= len(rowIndexes) {
+			values[rowIndex] = f.def
+			continue
+		}
+		values[rowIndex] = f.col.Values[rowIndexes[j]]
+	}
+
+	return nil
+}
+
+func (f offsetFunction) field() table.Field {
+	field := f.col.Field
+	field.Nullable = field.Nullable || f.def.Value() == nil
+
+	return field
+}
+
+// runningFunction вычисляет агрегатную функцию нарастающим итогом от начала раздела до последней строки,
+// равной текущей. Без сортировки функция вычисляется по всему разделу.
+type runningFunction struct {
+	t           table.Table
+	aggregate   table.Aggregate
+	resultField table.Field
+}
+
+func (f runningFunction) apply(ctx context.Context, rowIndexes, peers []int, values []table.Value) error {
+	running, _, err := aggregate.Running(ctx, f.t, rowIndexes, f.aggregate)
+	if err != nil {
+		return err
+	}
+	for i, rowIndex := range rowIndexes {
+		values[rowIndex] = running[peers[i]-1]
+	}
+
+	return nil
+}
+
+func (f runningFunction) field() table.Field {
+	return f.resultField
+}
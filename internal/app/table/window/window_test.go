@@ -0,0 +1,119 @@
+package window
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stepan2volkov/csvdb/internal/app/table"
+	"github.com/stepan2volkov/csvdb/internal/app/table/value"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTable() table.Table {
+	countries := []string{"France", "Japan", "France", "Japan", "France"}
+	months := []int64{2, 1, 1, 2, 2}
+	profits := []string{"30.5", "10", "20", "15.25", "5"}
+
+	countryValues := make([]table.Value, 0, len(countries))
+	monthValues := make([]table.Value, 0, len(months))
+	profitValues := make([]table.Value, 0, len(profits))
+	for i := range countries {
+		countryValues = append(countryValues, value.NewStringValue(countries[i]))
+		monthValues = append(monthValues, value.NewIntValueFromInt64(months[i]))
+		profit, _ := value.NewDecimalValue(profits[i], 2)
+		profitValues = append(profitValues, profit)
+	}
+
+	return table.NewTable("sales", []table.Column{
+		{Field: table.Field{Name: "country", Type: table.FieldTypeString}, Values: countryValues},
+		{Field: table.Field{Name: "month", Type: table.FieldTypeInt}, Values: monthValues},
+		{Field: table.Field{Name: "profit", Type: table.FieldTypeDecimal, Scale: 2}, Values: profitValues},
+	})
+}
+
+func TestApply(t *testing.T) {
+	byCountry := []string{"country"}
+	byMonth := []table.OrderField{{Name: "month"}}
+
+	tests := []struct {
+		name      string
+		window    table.Window
+		want      []string
+		wantField table.Field
+	}{
+		{
+			name:      "row number",
+			window:    table.Window{Function: table.WindowFunctionTypeRowNumber, PartitionBy: byCountry, OrderBy: byMonth},
+			want:      []string{"2", "1", "1", "2", "3"},
+			wantField: table.Field{Type: table.FieldTypeInt},
+		},
+		{
+			name:      "rank of equal rows",
+			window:    table.Window{Function: table.WindowFunctionTypeRank, OrderBy: byMonth},
+			want:      []string{"3", "1", "1", "3", "3"},
+			wantField: table.Field{Type: table.FieldTypeInt},
+		},
+		{
+			name: "lag",
+			window: table.Window{
+				Function: table.WindowFunctionTypeLag, ColumnName: "profit", Offset: 1,
+				PartitionBy: byCountry, OrderBy: byMonth,
+			},
+			want:      []string{"20.00", "NULL", "NULL", "10.00", "30.50"},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2, Nullable: true},
+		},
+		{
+			name: "lead with default",
+			window: table.Window{
+				Function: table.WindowFunctionTypeLead, ColumnName: "month", Offset: 2,
+				Default: value.NewIntValueFromInt64(0), OrderBy: []table.OrderField{{Name: "profit", Desc: true}},
+			},
+			want:      []string{"2", "0", "1", "2", "0"},
+			wantField: table.Field{Type: table.FieldTypeInt},
+		},
+		{
+			name: "running sum includes equal rows",
+			window: table.Window{
+				Function: table.WindowFunctionType(table.AggregateTypeSum), ColumnName: "profit",
+				PartitionBy: byCountry, OrderBy: byMonth,
+			},
+			want:      []string{"55.50", "10.00", "20.00", "25.25", "55.50"},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2, Nullable: true},
+		},
+		{
+			name: "sum without order",
+			window: table.Window{
+				Function: table.WindowFunctionType(table.AggregateTypeSum), ColumnName: "profit",
+			},
+			want:      []string{"80.75", "80.75", "80.75", "80.75", "80.75"},
+			wantField: table.Field{Type: table.FieldTypeDecimal, Scale: 2, Nullable: true},
+		},
+	}
+
+	tbl := newTestTable()
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(ctx, tbl, tbl.RowIndexes(), []table.Window{tt.window})
+			assert.NoError(t, err)
+			assert.Equal(t, len(tbl.Columns)+1, len(got.Columns))
+
+			col, err := got.GetColumnByName(tt.window.Name())
+			assert.NoError(t, err)
+			values := make([]string, 0, len(col.Values))
+			for _, val := range col.Values {
+				values = append(values, val.String())
+			}
+			assert.Equal(t, tt.want, values)
+
+			tt.wantField.Name = tt.window.Name()
+			assert.Equal(t, tt.wantField, col.Field)
+		})
+	}
+
+	_, err := Apply(ctx, tbl, tbl.RowIndexes(), []table.Window{
+		{Function: table.WindowFunctionType(table.AggregateTypeSum), ColumnName: "country"},
+	})
+	assert.Error(t, err)
+}